@@ -1,16 +1,17 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
 
 	"github.com/gofrs/uuid"
-	"github.com/quickaco/xerosdk/helpers"
+	"github.com/quickaco/xerosdk/xerotime"
 )
 
 const (
-	accountsURL = "https://api.xero.com/api.xro/2.0/Accounts"
+	accountsPath = "Accounts"
 )
 
 //Account represents individual accounts in a Xero organisation
@@ -68,7 +69,14 @@ type Account struct {
 	HasAttachments bool `json:"HasAttachments,omitempty"`
 
 	// Last modified date UTC format
-	UpdatedDateUTC string `json:"UpdatedDateUTC,omitempty"`
+	UpdatedDateUTC xerotime.DateTime `json:"UpdatedDateUTC,omitempty"`
+}
+
+// MarshalJSON method will leave out the zero dates and amounts, see
+// marshalOmitZero
+func (a Account) MarshalJSON() ([]byte, error) {
+	type account Account
+	return marshalOmitZero(account(a))
 }
 
 //Accounts contains a collection of Accounts
@@ -76,20 +84,6 @@ type Accounts struct {
 	Accounts []Account `json:"Accounts,omitempty"`
 }
 
-//The Xero API returns Dates based on the .Net JSON date format available at the time of development
-//We need to convert these to a more usable format - RFC3339 for consistency with what the API expects to recieve
-func (a *Accounts) convertDates() error {
-	var err error
-	for n := len(a.Accounts) - 1; n >= 0; n-- {
-		a.Accounts[n].UpdatedDateUTC, err = helpers.DotNetJSONTimeToRFC3339(a.Accounts[n].UpdatedDateUTC, true)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
 func unmarshalAccount(accountResponseBytes []byte) (*Accounts, error) {
 	var accountResponse *Accounts
 	err := json.Unmarshal(accountResponseBytes, &accountResponse)
@@ -97,32 +91,32 @@ func unmarshalAccount(accountResponseBytes []byte) (*Accounts, error) {
 		return nil, err
 	}
 
-	err = accountResponse.convertDates()
-	if err != nil {
-		return nil, err
-	}
+	return accountResponse, nil
+}
 
-	return accountResponse, err
+// AccountService gives access to the Accounts endpoint
+type AccountService struct {
+	s *Service
 }
 
-//FindAccountsModifiedSince will get all accounts modified after a specified date.
-//additional querystringParameters such as where and order can be added as a map
-func FindAccountsModifiedSince(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Accounts, error) {
-	additionalHeaders := map[string]string{}
-	additionalHeaders["If-Modified-Since"] = modifiedSince.Format(time.RFC3339)
+// Accounts method will return the service for the Accounts endpoint
+func (s *Service) Accounts() *AccountService {
+	return &AccountService{s: s}
+}
 
-	accountResponseBytes, err := helpers.Find(cl, accountsURL, additionalHeaders, queryParameters)
-	if err != nil {
-		return nil, err
-	}
+// List method will get all accounts, additional querystringParameters such as
+// where and order can be added as a map
+func (as *AccountService) List(ctx context.Context, queryParameters map[string]string) (*Accounts, error) {
+	return as.list(ctx, nil, queryParameters)
+}
 
-	return unmarshalAccount(accountResponseBytes)
+// ListModifiedSince method will get all accounts modified after the given date
+func (as *AccountService) ListModifiedSince(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*Accounts, error) {
+	return as.list(ctx, modifiedSinceHeaders(modifiedSince), queryParameters)
 }
 
-//FindAccounts will get all accounts. These account will not have details like line items.
-//additional querystringParameters such as where and order can be added as a map
-func FindAccounts(cl *http.Client, queryParameters map[string]string) (*Accounts, error) {
-	accountResponseBytes, err := helpers.Find(cl, accountsURL, nil, queryParameters)
+func (as *AccountService) list(ctx context.Context, additionalHeaders map[string]string, queryParameters map[string]string) (*Accounts, error) {
+	accountResponseBytes, err := as.s.find(withOperation(ctx, "Accounts", "List"), as.s.endpoint(accountsPath), additionalHeaders, queryParameters)
 	if err != nil {
 		return nil, err
 	}
@@ -130,9 +124,9 @@ func FindAccounts(cl *http.Client, queryParameters map[string]string) (*Accounts
 	return unmarshalAccount(accountResponseBytes)
 }
 
-//FindAccount will get a single account - accountID must be a GUID for an account
-func FindAccount(cl *http.Client, accountID uuid.UUID) (*Account, error) {
-	accountResponseBytes, err := helpers.Find(cl, accountsURL+"/"+accountID.String(), nil, nil)
+// Get method will get a single account - accountID must be a GUID for an account
+func (as *AccountService) Get(ctx context.Context, accountID uuid.UUID) (*Account, error) {
+	accountResponseBytes, err := as.s.find(withOperation(ctx, "Accounts", "Get"), as.s.endpoint(accountsPath, accountID.String()), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -146,9 +140,9 @@ func FindAccount(cl *http.Client, accountID uuid.UUID) (*Account, error) {
 	return nil, nil
 }
 
-// RemoveAccount will get a single account - accountID must be a GUID for an account
-func RemoveAccount(cl *http.Client, accountID uuid.UUID) (*Accounts, error) {
-	accountResponseBytes, err := helpers.Remove(cl, accountsURL+"/"+accountID.String())
+// Remove method will delete the account with the given accountID
+func (as *AccountService) Remove(ctx context.Context, accountID uuid.UUID) (*Accounts, error) {
+	accountResponseBytes, err := as.s.remove(withOperation(ctx, "Accounts", "Remove"), as.s.endpoint(accountsPath, accountID.String()))
 	if err != nil {
 		return nil, err
 	}
@@ -156,13 +150,13 @@ func RemoveAccount(cl *http.Client, accountID uuid.UUID) (*Accounts, error) {
 	return unmarshalAccount(accountResponseBytes)
 }
 
-// Create will create accounts given an Accounts struct
-func (a *Accounts) Create(cl *http.Client) (*Accounts, error) {
+// Create method will create the given accounts
+func (as *AccountService) Create(ctx context.Context, a *Accounts) (*Accounts, error) {
 	buf, err := json.Marshal(a)
 	if err != nil {
 		return nil, err
 	}
-	accountResponseBytes, err := helpers.Create(cl, accountsURL, buf)
+	accountResponseBytes, err := as.s.create(withOperation(ctx, "Accounts", "Create"), as.s.endpoint(accountsPath), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -170,9 +164,8 @@ func (a *Accounts) Create(cl *http.Client) (*Accounts, error) {
 	return unmarshalAccount(accountResponseBytes)
 }
 
-// Update will update an account given an Accounts struct
-// This will only handle single account - you cannot update multiple accounts in a single call
-func (a *Account) Update(cl *http.Client) (*Accounts, error) {
+// Update method will update the given account
+func (as *AccountService) Update(ctx context.Context, a *Account) (*Accounts, error) {
 	acc := Accounts{
 		Accounts: []Account{*a},
 	}
@@ -180,10 +173,73 @@ func (a *Account) Update(cl *http.Client) (*Accounts, error) {
 	if err != nil {
 		return nil, err
 	}
-	accountResponseBytes, err := helpers.Update(cl, accountsURL+"/"+a.AccountID, buf)
+	accountResponseBytes, err := as.s.update(withOperation(ctx, "Accounts", "Update"), as.s.endpoint(accountsPath, a.AccountID), buf)
 	if err != nil {
 		return nil, err
 	}
 
 	return unmarshalAccount(accountResponseBytes)
 }
+
+//FindAccountsModifiedSince will get all accounts modified after a specified date.
+//additional querystringParameters such as where and order can be added as a map
+func FindAccountsModifiedSince(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Accounts, error) {
+	return FindAccountsModifiedSinceContext(context.Background(), cl, modifiedSince, queryParameters)
+}
+
+// FindAccountsModifiedSinceContext is the same as FindAccountsModifiedSince but the given context is used for the request
+func FindAccountsModifiedSinceContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Accounts, error) {
+	return defaultService(cl).Accounts().ListModifiedSince(ctx, modifiedSince, queryParameters)
+}
+
+//FindAccounts will get all accounts. These account will not have details like line items.
+//additional querystringParameters such as where and order can be added as a map
+func FindAccounts(cl *http.Client, queryParameters map[string]string) (*Accounts, error) {
+	return FindAccountsContext(context.Background(), cl, queryParameters)
+}
+
+// FindAccountsContext is the same as FindAccounts but the given context is used for the request
+func FindAccountsContext(ctx context.Context, cl *http.Client, queryParameters map[string]string) (*Accounts, error) {
+	return defaultService(cl).Accounts().List(ctx, queryParameters)
+}
+
+//FindAccount will get a single account - accountID must be a GUID for an account
+func FindAccount(cl *http.Client, accountID uuid.UUID) (*Account, error) {
+	return FindAccountContext(context.Background(), cl, accountID)
+}
+
+// FindAccountContext is the same as FindAccount but the given context is used for the request
+func FindAccountContext(ctx context.Context, cl *http.Client, accountID uuid.UUID) (*Account, error) {
+	return defaultService(cl).Accounts().Get(ctx, accountID)
+}
+
+// RemoveAccount will get a single account - accountID must be a GUID for an account
+func RemoveAccount(cl *http.Client, accountID uuid.UUID) (*Accounts, error) {
+	return RemoveAccountContext(context.Background(), cl, accountID)
+}
+
+// RemoveAccountContext is the same as RemoveAccount but the given context is used for the request
+func RemoveAccountContext(ctx context.Context, cl *http.Client, accountID uuid.UUID) (*Accounts, error) {
+	return defaultService(cl).Accounts().Remove(ctx, accountID)
+}
+
+// Create will create accounts given an Accounts struct
+func (a *Accounts) Create(cl *http.Client) (*Accounts, error) {
+	return a.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the given context is used for the request
+func (a *Accounts) CreateContext(ctx context.Context, cl *http.Client) (*Accounts, error) {
+	return defaultService(cl).Accounts().Create(ctx, a)
+}
+
+// Update will update an account given an Accounts struct
+// This will only handle single account - you cannot update multiple accounts in a single call
+func (a *Account) Update(cl *http.Client) (*Accounts, error) {
+	return a.UpdateContext(context.Background(), cl)
+}
+
+// UpdateContext is the same as Update but the given context is used for the request
+func (a *Account) UpdateContext(ctx context.Context, cl *http.Client) (*Accounts, error) {
+	return defaultService(cl).Accounts().Update(ctx, a)
+}
@@ -1,18 +1,30 @@
 package accounting
 
+import (
+	"github.com/quickaco/xerosdk/decimal"
+	"github.com/quickaco/xerosdk/xerotime"
+)
+
 //Allocation allocated an overpayment or Prepayment to an Invoice
 type Allocation struct {
 
 	// the amount being applied to the invoice
-	AppliedAmount float64 `json:"AppliedAmount,omitempty"`
+	AppliedAmount decimal.Decimal `json:"AppliedAmount,omitempty"`
 
 	// the date the prepayment is applied YYYY-MM-DD (read-only). This will be the latter of the invoice date and the prepayment date.
-	Date string `json:"Date,omitempty"`
+	Date xerotime.Date `json:"Date,omitempty"`
 
 	//The Invoice that the allocation will be made to
 	Invoice InvoiceID `json:"Invoice,omitempty"`
 }
 
+// MarshalJSON method will leave out the zero dates and amounts, see
+// marshalOmitZero
+func (a Allocation) MarshalJSON() ([]byte, error) {
+	type allocation Allocation
+	return marshalOmitZero(allocation(a))
+}
+
 //Allocations is a collection of Allocations
 type Allocations struct {
 	Allocations []Allocation `json:"Allocations"`
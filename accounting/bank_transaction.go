@@ -1,16 +1,19 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
 
 	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/decimal"
 	"github.com/quickaco/xerosdk/helpers"
+	"github.com/quickaco/xerosdk/xerotime"
 )
 
 const (
-	bankTransactionURL = "https://api.xero.com/api.xro/2.0/BankTransactions"
+	bankTransactionPath = "BankTransactions"
 )
 
 //BankTransaction is a bank transaction
@@ -29,7 +32,7 @@ type BankTransaction struct {
 	IsReconciled bool `json:"IsReconciled,omitempty"`
 
 	// Date of transaction – YYYY-MM-DD
-	Date string `json:"DateString,omitempty"`
+	Date xerotime.Date `json:"DateString,omitempty"`
 
 	// Reference for the transaction. Only supported for SPEND and RECEIVE transactions.
 	Reference string `json:"Reference,omitempty"`
@@ -38,7 +41,7 @@ type BankTransaction struct {
 	CurrencyCode string `json:"CurrencyCode,omitempty"`
 
 	// Exchange rate to base currency when money is spent or received. e.g. 0.7500 Only used for bank transactions in non base currency. If this isn’t specified for non base currency accounts then either the user-defined rate (preference) or the XE.com day rate will be used. Setting currency is only supported on overpayments.
-	CurrencyRate float64 `json:"CurrencyRate,omitempty"`
+	CurrencyRate decimal.Decimal `json:"CurrencyRate,omitempty"`
 
 	// URL link to a source document – shown as “Go to App Name”
 	URL string `json:"Url,omitempty"`
@@ -50,13 +53,13 @@ type BankTransaction struct {
 	LineAmountTypes string `json:"LineAmountTypes,omitempty"`
 
 	// Total of bank transaction excluding taxes
-	SubTotal float64 `json:"SubTotal,omitempty"`
+	SubTotal decimal.Decimal `json:"SubTotal,omitempty"`
 
 	// Total tax on bank transaction
-	TotalTax float64 `json:"TotalTax,omitempty"`
+	TotalTax decimal.Decimal `json:"TotalTax,omitempty"`
 
 	// Total of bank transaction tax inclusive
-	Total float64 `json:"Total,omitempty"`
+	Total decimal.Decimal `json:"Total,omitempty"`
 
 	// Xero generated unique identifier for bank transaction
 	BankTransactionID string `json:"BankTransactionID,omitempty"`
@@ -71,10 +74,25 @@ type BankTransaction struct {
 	OverpaymentID string `json:"OverpaymentID,omitempty"`
 
 	// Last modified date UTC format
-	UpdatedDateUTC string `json:"UpdatedDateUTC,omitempty"`
+	UpdatedDateUTC xerotime.DateTime `json:"UpdatedDateUTC,omitempty"`
 
 	// Boolean to indicate if a bank transaction has an attachment
 	HasAttachments bool `json:"HasAttachments,omitempty"`
+
+	// Status of the element when it's sent with summarizeErrors=false, OK or
+	// ERROR
+	StatusAttributeString string `json:"StatusAttributeString,omitempty"`
+
+	// Validation errors of the element when it's sent with
+	// summarizeErrors=false
+	ValidationErrors []helpers.ValidationError `json:"ValidationErrors,omitempty"`
+}
+
+// MarshalJSON method will leave out the zero dates and amounts, see
+// marshalOmitZero
+func (b BankTransaction) MarshalJSON() ([]byte, error) {
+	type bankTransaction BankTransaction
+	return marshalOmitZero(bankTransaction(b))
 }
 
 //BankTransactions contains a collection of BankTransactions
@@ -82,20 +100,6 @@ type BankTransactions struct {
 	BankTransactions []BankTransaction `json:"BankTransactions"`
 }
 
-//The Xero API returns Dates based on the .Net JSON date format available at the time of development
-//We need to convert these to a more usable format - RFC3339 for consistency with what the API expects to recieve
-func (b *BankTransactions) convertDates() error {
-	var err error
-	for n := len(b.BankTransactions) - 1; n >= 0; n-- {
-		b.BankTransactions[n].UpdatedDateUTC, err = helpers.DotNetJSONTimeToRFC3339(b.BankTransactions[n].UpdatedDateUTC, true)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
 func unmarshalBankTransaction(bankTransactionResponseBytes []byte) (*BankTransactions, error) {
 	var bankTransactionResponse *BankTransactions
 	err := json.Unmarshal(bankTransactionResponseBytes, &bankTransactionResponse)
@@ -103,35 +107,34 @@ func unmarshalBankTransaction(bankTransactionResponseBytes []byte) (*BankTransac
 		return nil, err
 	}
 
-	err = bankTransactionResponse.convertDates()
-	if err != nil {
-		return nil, err
-	}
+	return bankTransactionResponse, nil
+}
 
-	return bankTransactionResponse, err
+// BankTransactionService gives access to the BankTransactions endpoint
+type BankTransactionService struct {
+	s *Service
 }
 
-// FindBankTransactions will get all BankTransactions. These BankTransaction will not have details like line items by default.
-// If you need details then then add a 'page' querystringParameter and get 100 BankTransactions at a time
-// additional querystringParameters such as where, page, order can be added as a map
-func FindBankTransactions(cl *http.Client, queryParameters map[string]string) (*BankTransactions, error) {
-	bankTransactionsBytes, err := helpers.Find(cl, bankTransactionURL, nil, queryParameters)
-	if err != nil {
-		return nil, err
-	}
+// BankTransactions method will return the service for the BankTransactions
+// endpoint
+func (s *Service) BankTransactions() *BankTransactionService {
+	return &BankTransactionService{s: s}
+}
 
-	return unmarshalBankTransaction(bankTransactionsBytes)
+// List method will get the BankTransactions, additional querystringParameters
+// such as where, page and order can be added as a map
+func (bs *BankTransactionService) List(ctx context.Context, queryParameters map[string]string) (*BankTransactions, error) {
+	return bs.list(ctx, nil, queryParameters)
 }
 
-// FindBankTransactionsModifiedSince will get all BankTransactions modified after a specified date.
-// These BankTransactions will not have details like default account codes and tracking categories by default.
-// If you need details then then add a 'page' querystringParameter and get 100 BankTransactions at a time
-// additional querystringParameters such as where, page, order can be added as a map
-func FindBankTransactionsModifiedSince(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*BankTransactions, error) {
-	additionalHeaders := map[string]string{}
-	additionalHeaders["If-Modified-Since"] = modifiedSince.Format(time.RFC3339)
+// ListModifiedSince method will get the BankTransactions modified after the
+// given date
+func (bs *BankTransactionService) ListModifiedSince(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*BankTransactions, error) {
+	return bs.list(ctx, modifiedSinceHeaders(modifiedSince), queryParameters)
+}
 
-	bankTransactionsBytes, err := helpers.Find(cl, bankTransactionURL, additionalHeaders, queryParameters)
+func (bs *BankTransactionService) list(ctx context.Context, additionalHeaders map[string]string, queryParameters map[string]string) (*BankTransactions, error) {
+	bankTransactionsBytes, err := bs.s.find(withOperation(ctx, "BankTransactions", "List"), bs.s.endpoint(bankTransactionPath), additionalHeaders, queryParameters)
 	if err != nil {
 		return nil, err
 	}
@@ -139,9 +142,9 @@ func FindBankTransactionsModifiedSince(cl *http.Client, modifiedSince time.Time,
 	return unmarshalBankTransaction(bankTransactionsBytes)
 }
 
-//FindBankTransaction will get a single BankTransaction - BankTransactionID can be a GUID for an BankTransaction or an BankTransaction number
-func FindBankTransaction(cl *http.Client, bankTransactionID uuid.UUID) (*BankTransaction, error) {
-	bankTransactionBytes, err := helpers.Find(cl, bankTransactionURL+"/"+bankTransactionID.String(), nil, nil)
+// Get method will get a single BankTransaction
+func (bs *BankTransactionService) Get(ctx context.Context, bankTransactionID uuid.UUID) (*BankTransaction, error) {
+	bankTransactionBytes, err := bs.s.find(withOperation(ctx, "BankTransactions", "Get"), bs.s.endpoint(bankTransactionPath, bankTransactionID.String()), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -155,13 +158,13 @@ func FindBankTransaction(cl *http.Client, bankTransactionID uuid.UUID) (*BankTra
 	return nil, nil
 }
 
-// Create will create accounts given an Accounts struct
-func (b *BankTransactions) Create(cl *http.Client) (*BankTransactions, error) {
+// Create method will create the given BankTransactions
+func (bs *BankTransactionService) Create(ctx context.Context, b *BankTransactions) (*BankTransactions, error) {
 	buf, err := json.Marshal(b)
 	if err != nil {
 		return nil, err
 	}
-	bankTransactionBytes, err := helpers.Create(cl, bankTransactionURL, buf)
+	bankTransactionBytes, err := bs.s.create(withOperation(ctx, "BankTransactions", "Create"), bs.s.endpoint(bankTransactionPath), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -169,9 +172,8 @@ func (b *BankTransactions) Create(cl *http.Client) (*BankTransactions, error) {
 	return unmarshalBankTransaction(bankTransactionBytes)
 }
 
-// Update will update an account given an Accounts struct
-// This will only handle single account - you cannot update multiple accounts in a single call
-func (b *BankTransaction) Update(cl *http.Client) (*BankTransactions, error) {
+// Update method will update the given BankTransaction
+func (bs *BankTransactionService) Update(ctx context.Context, b *BankTransaction) (*BankTransactions, error) {
 	bt := BankTransactions{
 		BankTransactions: []BankTransaction{*b},
 	}
@@ -179,10 +181,66 @@ func (b *BankTransaction) Update(cl *http.Client) (*BankTransactions, error) {
 	if err != nil {
 		return nil, err
 	}
-	bankTransactionBytes, err := helpers.Update(cl, bankTransactionURL+"/"+b.BankTransactionID, buf)
+	bankTransactionBytes, err := bs.s.update(withOperation(ctx, "BankTransactions", "Update"), bs.s.endpoint(bankTransactionPath, b.BankTransactionID), buf)
 	if err != nil {
 		return nil, err
 	}
 
 	return unmarshalBankTransaction(bankTransactionBytes)
 }
+
+// FindBankTransactions will get all BankTransactions. These BankTransaction will not have details like line items by default.
+// If you need details then then add a 'page' querystringParameter and get 100 BankTransactions at a time
+// additional querystringParameters such as where, page, order can be added as a map
+func FindBankTransactions(cl *http.Client, queryParameters map[string]string) (*BankTransactions, error) {
+	return FindBankTransactionsContext(context.Background(), cl, queryParameters)
+}
+
+// FindBankTransactionsContext is the same as FindBankTransactions but the given context is used for the request
+func FindBankTransactionsContext(ctx context.Context, cl *http.Client, queryParameters map[string]string) (*BankTransactions, error) {
+	return defaultService(cl).BankTransactions().List(ctx, queryParameters)
+}
+
+// FindBankTransactionsModifiedSince will get all BankTransactions modified after a specified date.
+// These BankTransactions will not have details like default account codes and tracking categories by default.
+// If you need details then then add a 'page' querystringParameter and get 100 BankTransactions at a time
+// additional querystringParameters such as where, page, order can be added as a map
+func FindBankTransactionsModifiedSince(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*BankTransactions, error) {
+	return FindBankTransactionsModifiedSinceContext(context.Background(), cl, modifiedSince, queryParameters)
+}
+
+// FindBankTransactionsModifiedSinceContext is the same as FindBankTransactionsModifiedSince but the given context is used for the request
+func FindBankTransactionsModifiedSinceContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*BankTransactions, error) {
+	return defaultService(cl).BankTransactions().ListModifiedSince(ctx, modifiedSince, queryParameters)
+}
+
+//FindBankTransaction will get a single BankTransaction - BankTransactionID can be a GUID for an BankTransaction or an BankTransaction number
+func FindBankTransaction(cl *http.Client, bankTransactionID uuid.UUID) (*BankTransaction, error) {
+	return FindBankTransactionContext(context.Background(), cl, bankTransactionID)
+}
+
+// FindBankTransactionContext is the same as FindBankTransaction but the given context is used for the request
+func FindBankTransactionContext(ctx context.Context, cl *http.Client, bankTransactionID uuid.UUID) (*BankTransaction, error) {
+	return defaultService(cl).BankTransactions().Get(ctx, bankTransactionID)
+}
+
+// Create will create accounts given an Accounts struct
+func (b *BankTransactions) Create(cl *http.Client) (*BankTransactions, error) {
+	return b.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the given context is used for the request
+func (b *BankTransactions) CreateContext(ctx context.Context, cl *http.Client) (*BankTransactions, error) {
+	return defaultService(cl).BankTransactions().Create(ctx, b)
+}
+
+// Update will update an account given an Accounts struct
+// This will only handle single account - you cannot update multiple accounts in a single call
+func (b *BankTransaction) Update(cl *http.Client) (*BankTransactions, error) {
+	return b.UpdateContext(context.Background(), cl)
+}
+
+// UpdateContext is the same as Update but the given context is used for the request
+func (b *BankTransaction) UpdateContext(ctx context.Context, cl *http.Client) (*BankTransactions, error) {
+	return defaultService(cl).BankTransactions().Update(ctx, b)
+}
@@ -1,32 +1,35 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
 
 	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/decimal"
 	"github.com/quickaco/xerosdk/helpers"
+	"github.com/quickaco/xerosdk/xerotime"
 )
 
 const (
-	bankTransferURL = "https://api.xero.com/api.xro/2.0/BankTransfers"
+	bankTransferPath = "BankTransfers"
 )
 
 //BankTransfer is a record of monies transferred from one bank account to another
 type BankTransfer struct {
 
 	//
-	Amount float64 `json:"Amount"`
+	Amount decimal.Decimal `json:"Amount"`
 
 	// The date of the Transfer YYYY-MM-DD
-	Date string `json:"Date,omitempty"`
+	Date xerotime.Date `json:"Date,omitempty"`
 
 	// The identifier of the Bank Transfer
 	BankTransferID string `json:"BankTransferID,omitempty"`
 
 	// The currency rate
-	CurrencyRate float64 `json:"CurrencyRate,omitempty"`
+	CurrencyRate decimal.Decimal `json:"CurrencyRate,omitempty"`
 
 	// The Bank Transaction ID for the source account
 	FromBankTransactionID string `json:"FromBankTransactionID,omitempty"`
@@ -38,13 +41,28 @@ type BankTransfer struct {
 	HasAttachments bool `json:"HasAttachments,omitempty"`
 
 	// UTC timestamp of creation date of bank transfer
-	CreatedDateUTC string `json:"CreatedDateUTC,omitempty"`
+	CreatedDateUTC xerotime.DateTime `json:"CreatedDateUTC,omitempty"`
 
 	// The source BankAccount
 	FromBankAccount BankAccount `json:"FromBankAccount,omitempty"`
 
 	// The destination BankAccount
 	ToBankAccount BankAccount `json:"ToBankAccount,omitempty"`
+
+	// Status of the element when it's sent with summarizeErrors=false, OK or
+	// ERROR
+	StatusAttributeString string `json:"StatusAttributeString,omitempty"`
+
+	// Validation errors of the element when it's sent with
+	// summarizeErrors=false
+	ValidationErrors []helpers.ValidationError `json:"ValidationErrors,omitempty"`
+}
+
+// MarshalJSON method will leave out the zero dates and amounts, see
+// marshalOmitZero
+func (b BankTransfer) MarshalJSON() ([]byte, error) {
+	type bankTransfer BankTransfer
+	return marshalOmitZero(bankTransfer(b))
 }
 
 //BankTransfers contains a collection of BankTransfers
@@ -52,24 +70,6 @@ type BankTransfers struct {
 	BankTransfers []BankTransfer `json:"BankTransfers"`
 }
 
-//The Xero API returns Dates based on the .Net JSON date format available at the time of development
-//We need to convert these to a more usable format - RFC3339 for consistency with what the API expects to recieve
-func (b *BankTransfers) convertDates() error {
-	var err error
-	for n := len(b.BankTransfers) - 1; n >= 0; n-- {
-		b.BankTransfers[n].Date, err = helpers.DotNetJSONTimeToRFC3339(b.BankTransfers[n].Date, false)
-		if err != nil {
-			return err
-		}
-		b.BankTransfers[n].CreatedDateUTC, err = helpers.DotNetJSONTimeToRFC3339(b.BankTransfers[n].CreatedDateUTC, true)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
 func unmarshalBankTransfer(bankTransferResponseBytes []byte) (*BankTransfers, error) {
 	var bankTransferResponse *BankTransfers
 	err := json.Unmarshal(bankTransferResponseBytes, &bankTransferResponse)
@@ -77,35 +77,33 @@ func unmarshalBankTransfer(bankTransferResponseBytes []byte) (*BankTransfers, er
 		return nil, err
 	}
 
-	err = bankTransferResponse.convertDates()
-	if err != nil {
-		return nil, err
-	}
+	return bankTransferResponse, nil
+}
 
-	return bankTransferResponse, err
+// BankTransferService gives access to the BankTransfers endpoint
+type BankTransferService struct {
+	s *Service
 }
 
-// FindBankTransfersModifiedSince will get all BankTransfers modified after a specified date.
-// These BankTransfers will not have details like default line items by default.
-// If you need details then add a 'page' querystringParameter and get 100 BankTransfers at a time
-// additional querystringParameters such as where and order can be added as a map
-func FindBankTransfersModifiedSince(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*BankTransfers, error) {
-	additionalHeaders := map[string]string{}
-	additionalHeaders["If-Modified-Since"] = modifiedSince.Format(time.RFC3339)
+// BankTransfers method will return the service for the BankTransfers endpoint
+func (s *Service) BankTransfers() *BankTransferService {
+	return &BankTransferService{s: s}
+}
 
-	bankTransferBytes, err := helpers.Find(cl, bankTransferURL, additionalHeaders, queryParameters)
-	if err != nil {
-		return nil, err
-	}
+// List method will get the BankTransfers, additional querystringParameters
+// such as where, page and order can be added as a map
+func (bs *BankTransferService) List(ctx context.Context, queryParameters map[string]string) (*BankTransfers, error) {
+	return bs.list(ctx, nil, queryParameters)
+}
 
-	return unmarshalBankTransfer(bankTransferBytes)
+// ListModifiedSince method will get the BankTransfers modified after the given
+// date
+func (bs *BankTransferService) ListModifiedSince(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*BankTransfers, error) {
+	return bs.list(ctx, modifiedSinceHeaders(modifiedSince), queryParameters)
 }
 
-// FindBankTransfers will get all BankTransfers. These BankTransfer will not have details like line items by default.
-// If you need details then add a 'page' querystringParameter and get 100 BankTransfers at a time
-// additional querystringParameters such as where and order can be added as a map
-func FindBankTransfers(cl *http.Client, queryParameters map[string]string) (*BankTransfers, error) {
-	bankTransferBytes, err := helpers.Find(cl, bankTransferURL, nil, queryParameters)
+func (bs *BankTransferService) list(ctx context.Context, additionalHeaders map[string]string, queryParameters map[string]string) (*BankTransfers, error) {
+	bankTransferBytes, err := bs.s.find(withOperation(ctx, "BankTransfers", "List"), bs.s.endpoint(bankTransferPath), additionalHeaders, queryParameters)
 	if err != nil {
 		return nil, err
 	}
@@ -113,9 +111,9 @@ func FindBankTransfers(cl *http.Client, queryParameters map[string]string) (*Ban
 	return unmarshalBankTransfer(bankTransferBytes)
 }
 
-// FindBankTransfer will get a single bankTransfer - bankTransferID can be a GUID for an bankTransfer or an bankTransfer number
-func FindBankTransfer(cl *http.Client, bankTransferID uuid.UUID) (*BankTransfer, error) {
-	bankTransferBytes, err := helpers.Find(cl, bankTransferURL+"/"+bankTransferID.String(), nil, nil)
+// Get method will get a single BankTransfer
+func (bs *BankTransferService) Get(ctx context.Context, bankTransferID uuid.UUID) (*BankTransfer, error) {
+	bankTransferBytes, err := bs.s.find(withOperation(ctx, "BankTransfers", "Get"), bs.s.endpoint(bankTransferPath, bankTransferID.String()), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -129,16 +127,61 @@ func FindBankTransfer(cl *http.Client, bankTransferID uuid.UUID) (*BankTransfer,
 	return nil, nil
 }
 
-// Create will create bankTransfers given a BankTransfers struct
-func (b *BankTransfers) Create(cl *http.Client) (*BankTransfers, error) {
+// Create method will create the given BankTransfers
+func (bs *BankTransferService) Create(ctx context.Context, b *BankTransfers) (*BankTransfers, error) {
 	buf, err := json.Marshal(b)
 	if err != nil {
 		return nil, err
 	}
-	bankTransferBytes, err := helpers.Create(cl, bankTransferURL, buf)
+	bankTransferBytes, err := bs.s.create(withOperation(ctx, "BankTransfers", "Create"), bs.s.endpoint(bankTransferPath), buf)
 	if err != nil {
 		return nil, err
 	}
 
 	return unmarshalBankTransfer(bankTransferBytes)
 }
+
+// FindBankTransfersModifiedSince will get all BankTransfers modified after a specified date.
+// These BankTransfers will not have details like default line items by default.
+// If you need details then add a 'page' querystringParameter and get 100 BankTransfers at a time
+// additional querystringParameters such as where and order can be added as a map
+func FindBankTransfersModifiedSince(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*BankTransfers, error) {
+	return FindBankTransfersModifiedSinceContext(context.Background(), cl, modifiedSince, queryParameters)
+}
+
+// FindBankTransfersModifiedSinceContext is the same as FindBankTransfersModifiedSince but the given context is used for the request
+func FindBankTransfersModifiedSinceContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*BankTransfers, error) {
+	return defaultService(cl).BankTransfers().ListModifiedSince(ctx, modifiedSince, queryParameters)
+}
+
+// FindBankTransfers will get all BankTransfers. These BankTransfer will not have details like line items by default.
+// If you need details then add a 'page' querystringParameter and get 100 BankTransfers at a time
+// additional querystringParameters such as where and order can be added as a map
+func FindBankTransfers(cl *http.Client, queryParameters map[string]string) (*BankTransfers, error) {
+	return FindBankTransfersContext(context.Background(), cl, queryParameters)
+}
+
+// FindBankTransfersContext is the same as FindBankTransfers but the given context is used for the request
+func FindBankTransfersContext(ctx context.Context, cl *http.Client, queryParameters map[string]string) (*BankTransfers, error) {
+	return defaultService(cl).BankTransfers().List(ctx, queryParameters)
+}
+
+// FindBankTransfer will get a single bankTransfer - bankTransferID can be a GUID for an bankTransfer or an bankTransfer number
+func FindBankTransfer(cl *http.Client, bankTransferID uuid.UUID) (*BankTransfer, error) {
+	return FindBankTransferContext(context.Background(), cl, bankTransferID)
+}
+
+// FindBankTransferContext is the same as FindBankTransfer but the given context is used for the request
+func FindBankTransferContext(ctx context.Context, cl *http.Client, bankTransferID uuid.UUID) (*BankTransfer, error) {
+	return defaultService(cl).BankTransfers().Get(ctx, bankTransferID)
+}
+
+// Create will create bankTransfers given a BankTransfers struct
+func (b *BankTransfers) Create(cl *http.Client) (*BankTransfers, error) {
+	return b.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the given context is used for the request
+func (b *BankTransfers) CreateContext(ctx context.Context, cl *http.Client) (*BankTransfers, error) {
+	return defaultService(cl).BankTransfers().Create(ctx, b)
+}
@@ -0,0 +1,305 @@
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/quickaco/xerosdk/helpers"
+)
+
+const (
+	// BatchStatusOK is the status of an element saved by a batch call
+	BatchStatusOK = "OK"
+	// BatchStatusError is the status of an element rejected by a batch call
+	BatchStatusError = "ERROR"
+	// BatchStatusSkipped is the status of an element of a bulk call that was
+	// not sent because its chunk was already done by the resumed call
+	BatchStatusSkipped = "SKIPPED"
+)
+
+// ErrResultCount is returned by a batch call when the response doesn't have
+// a result per element of the request, the results can't be matched then
+var ErrResultCount = errors.New("accounting: the response doesn't have a result per element")
+
+// BatchResult keeps the outcome of one element of a batch call, Index is the
+// position of the element in the request. Err is set when the call carrying
+// the element failed as a whole, or was never made
+type BatchResult struct {
+	Index            int
+	Status           string
+	ValidationErrors []helpers.ValidationError
+	Err              error
+}
+
+// newBatchResult will build the result of an element, the elements answered
+// without a status nor validation errors, such as the echo of a dry run, are OK
+func newBatchResult(index int, status string, validationErrors []helpers.ValidationError) BatchResult {
+	if status == "" && len(validationErrors) == 0 {
+		status = BatchStatusOK
+	}
+	return BatchResult{
+		Index:            index,
+		Status:           status,
+		ValidationErrors: validationErrors,
+	}
+}
+
+// OK method will return true when the element was saved, that is when Xero
+// answered it with the OK status, or with no status and no validation errors. The elements skipped by a resumed bulk
+// write are not OK, their Status tells them apart
+func (r BatchResult) OK() bool {
+	return r.Err == nil && r.Status == BatchStatusOK && len(r.ValidationErrors) == 0
+}
+
+// InvoiceResult is the outcome of one invoice of a batch call
+type InvoiceResult struct {
+	BatchResult
+	Invoice Invoice
+}
+
+// CreateBatch method will create the given invoices returning a result per
+// invoice, the ones that fail validation don't prevent the others from being
+// created
+func (is *InvoiceService) CreateBatch(ctx context.Context, i *Invoices) ([]InvoiceResult, error) {
+	return is.batch(withOperation(ctx, "Invoices", "CreateBatch"), is.s.createBatch, i)
+}
+
+// UpdateBatch method will update the given invoices returning a result per
+// invoice, the ones that fail validation don't prevent the others from being
+// updated. The invoices without an identifier are created
+func (is *InvoiceService) UpdateBatch(ctx context.Context, i *Invoices) ([]InvoiceResult, error) {
+	return is.batch(withOperation(ctx, "Invoices", "UpdateBatch"), is.s.updateBatch, i)
+}
+
+func (is *InvoiceService) batch(ctx context.Context, send func(ctx context.Context, endpoint string, body []byte) ([]byte, error), i *Invoices) ([]InvoiceResult, error) {
+	buf, err := json.Marshal(i)
+	if err != nil {
+		return nil, err
+	}
+	invoiceResponseBytes, err := send(ctx, is.s.endpoint(invoicePath), buf)
+	if err != nil {
+		return nil, err
+	}
+	invoices, err := unmarshalInvoice(invoiceResponseBytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResultCount(len(i.Invoices), len(invoices.Invoices)); err != nil {
+		return nil, err
+	}
+	results := make([]InvoiceResult, 0, len(invoices.Invoices))
+	for index, invoice := range invoices.Invoices {
+		results = append(results, InvoiceResult{
+			BatchResult: newBatchResult(index, invoice.StatusAttributeString, invoice.ValidationErrors),
+			Invoice:     invoice,
+		})
+	}
+	return results, nil
+}
+
+// ContactResult is the outcome of one contact of a batch call
+type ContactResult struct {
+	BatchResult
+	Contact Contact
+}
+
+// CreateBatch method will create the given contacts returning a result per
+// contact, the ones that fail validation don't prevent the others from being
+// created
+func (cs *ContactService) CreateBatch(ctx context.Context, c *Contacts) ([]ContactResult, error) {
+	return cs.batch(withOperation(ctx, "Contacts", "CreateBatch"), cs.s.createBatch, c)
+}
+
+// UpdateBatch method will update the given contacts returning a result per
+// contact, the ones that fail validation don't prevent the others from being
+// updated. The contacts without an identifier are created
+func (cs *ContactService) UpdateBatch(ctx context.Context, c *Contacts) ([]ContactResult, error) {
+	return cs.batch(withOperation(ctx, "Contacts", "UpdateBatch"), cs.s.updateBatch, c)
+}
+
+func (cs *ContactService) batch(ctx context.Context, send func(ctx context.Context, endpoint string, body []byte) ([]byte, error), c *Contacts) ([]ContactResult, error) {
+	buf, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	contactResponseBytes, err := send(ctx, cs.s.endpoint(contactsPath), buf)
+	if err != nil {
+		return nil, err
+	}
+	contacts, err := unmarshalContact(contactResponseBytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResultCount(len(c.Contacts), len(contacts.Contacts)); err != nil {
+		return nil, err
+	}
+	results := make([]ContactResult, 0, len(contacts.Contacts))
+	for index, contact := range contacts.Contacts {
+		results = append(results, ContactResult{
+			BatchResult: newBatchResult(index, contact.StatusAttributeString, contact.ValidationErrors),
+			Contact:     contact,
+		})
+	}
+	return results, nil
+}
+
+// ItemResult is the outcome of one item of a batch call
+type ItemResult struct {
+	BatchResult
+	Item Item
+}
+
+// CreateBatch method will create the given items returning a result per item,
+// the ones that fail validation don't prevent the others from being created
+func (is *ItemService) CreateBatch(ctx context.Context, i *Items) ([]ItemResult, error) {
+	return is.batch(withOperation(ctx, "Items", "CreateBatch"), is.s.createBatch, i)
+}
+
+// UpdateBatch method will update the given items returning a result per
+// item, the ones that fail validation don't prevent the others from being
+// updated. The items without an identifier are created
+func (is *ItemService) UpdateBatch(ctx context.Context, i *Items) ([]ItemResult, error) {
+	return is.batch(withOperation(ctx, "Items", "UpdateBatch"), is.s.updateBatch, i)
+}
+
+func (is *ItemService) batch(ctx context.Context, send func(ctx context.Context, endpoint string, body []byte) ([]byte, error), i *Items) ([]ItemResult, error) {
+	buf, err := json.Marshal(i)
+	if err != nil {
+		return nil, err
+	}
+	itemsResponseBytes, err := send(ctx, is.s.endpoint(itemPath), buf)
+	if err != nil {
+		return nil, err
+	}
+	items, err := unmarshalItem(itemsResponseBytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResultCount(len(i.Items), len(items.Items)); err != nil {
+		return nil, err
+	}
+	results := make([]ItemResult, 0, len(items.Items))
+	for index, item := range items.Items {
+		results = append(results, ItemResult{
+			BatchResult: newBatchResult(index, item.StatusAttributeString, item.ValidationErrors),
+			Item:        item,
+		})
+	}
+	return results, nil
+}
+
+// BankTransactionResult is the outcome of one bank transaction of a batch call
+type BankTransactionResult struct {
+	BatchResult
+	BankTransaction BankTransaction
+}
+
+// CreateBatch method will create the given bank transactions returning a
+// result per bank transaction, the ones that fail validation don't prevent
+// the others from being created
+func (bs *BankTransactionService) CreateBatch(ctx context.Context, b *BankTransactions) ([]BankTransactionResult, error) {
+	buf, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	bankTransactionResponseBytes, err := bs.s.createBatch(withOperation(ctx, "BankTransactions", "CreateBatch"), bs.s.endpoint(bankTransactionPath), buf)
+	if err != nil {
+		return nil, err
+	}
+	bankTransactions, err := unmarshalBankTransaction(bankTransactionResponseBytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResultCount(len(b.BankTransactions), len(bankTransactions.BankTransactions)); err != nil {
+		return nil, err
+	}
+	results := make([]BankTransactionResult, 0, len(bankTransactions.BankTransactions))
+	for index, bankTransaction := range bankTransactions.BankTransactions {
+		results = append(results, BankTransactionResult{
+			BatchResult:     newBatchResult(index, bankTransaction.StatusAttributeString, bankTransaction.ValidationErrors),
+			BankTransaction: bankTransaction,
+		})
+	}
+	return results, nil
+}
+
+// CreditNoteResult is the outcome of one credit note of a batch call
+type CreditNoteResult struct {
+	BatchResult
+	CreditNote CreditNote
+}
+
+// CreateBatch method will create the given credit notes returning a result
+// per credit note, the ones that fail validation don't prevent the others
+// from being created
+func (cs *CreditNoteService) CreateBatch(ctx context.Context, c *CreditNotes) ([]CreditNoteResult, error) {
+	buf, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	creditNotesBytes, err := cs.s.createBatch(withOperation(ctx, "CreditNotes", "CreateBatch"), cs.s.endpoint(creditNotesPath), buf)
+	if err != nil {
+		return nil, err
+	}
+	creditNotes, err := unmarshalCreditNote(creditNotesBytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResultCount(len(c.CreditNotes), len(creditNotes.CreditNotes)); err != nil {
+		return nil, err
+	}
+	results := make([]CreditNoteResult, 0, len(creditNotes.CreditNotes))
+	for index, creditNote := range creditNotes.CreditNotes {
+		results = append(results, CreditNoteResult{
+			BatchResult: newBatchResult(index, creditNote.StatusAttributeString, creditNote.ValidationErrors),
+			CreditNote:  creditNote,
+		})
+	}
+	return results, nil
+}
+
+// BankTransferResult is the outcome of one bank transfer of a batch call
+type BankTransferResult struct {
+	BatchResult
+	BankTransfer BankTransfer
+}
+
+// CreateBatch method will create the given bank transfers returning a result
+// per bank transfer, the ones that fail validation don't prevent the others
+// from being created
+func (bs *BankTransferService) CreateBatch(ctx context.Context, b *BankTransfers) ([]BankTransferResult, error) {
+	buf, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	bankTransferResponseBytes, err := bs.s.createBatch(withOperation(ctx, "BankTransfers", "CreateBatch"), bs.s.endpoint(bankTransferPath), buf)
+	if err != nil {
+		return nil, err
+	}
+	bankTransfers, err := unmarshalBankTransfer(bankTransferResponseBytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResultCount(len(b.BankTransfers), len(bankTransfers.BankTransfers)); err != nil {
+		return nil, err
+	}
+	results := make([]BankTransferResult, 0, len(bankTransfers.BankTransfers))
+	for index, bankTransfer := range bankTransfers.BankTransfers {
+		results = append(results, BankTransferResult{
+			BatchResult:  newBatchResult(index, bankTransfer.StatusAttributeString, bankTransfer.ValidationErrors),
+			BankTransfer: bankTransfer,
+		})
+	}
+	return results, nil
+}
+
+// checkResultCount will make sure a batch response has a result per element
+// sent, as they are matched by position
+func checkResultCount(sent int, received int) error {
+	if sent != received {
+		return fmt.Errorf("%w: %d results for %d elements", ErrResultCount, received, sent)
+	}
+	return nil
+}
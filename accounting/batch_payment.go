@@ -1,14 +1,13 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
-
-	"github.com/quickaco/xerosdk/helpers"
 )
 
 const (
-	batchPaymentURL = "https://api.xero.com/api.xro/2.0/BatchPayments"
+	batchPaymentPath = "BatchPayments"
 )
 
 // BatchPayment type will keep information related with a bank
@@ -41,11 +40,31 @@ func unmarshalBatchPayment(batchPaymentResponseBytes []byte) ([]BatchPayment, er
 	return response.Payments, nil
 }
 
-// FindBatchPayments will get all the batch payments
-func FindBatchPayments(cl *http.Client) ([]BatchPayment, error) {
-	batchPayments, err := helpers.Find(cl, batchPaymentURL, nil, nil)
+// BatchPaymentService gives access to the BatchPayments endpoint
+type BatchPaymentService struct {
+	s *Service
+}
+
+// BatchPayments method will return the service for the BatchPayments endpoint
+func (s *Service) BatchPayments() *BatchPaymentService {
+	return &BatchPaymentService{s: s}
+}
+
+// List method will get all the batch payments
+func (bs *BatchPaymentService) List(ctx context.Context) ([]BatchPayment, error) {
+	batchPayments, err := bs.s.find(withOperation(ctx, "BatchPayments", "List"), bs.s.endpoint(batchPaymentPath), nil, nil)
 	if err != nil {
 		return nil, err
 	}
 	return unmarshalBatchPayment(batchPayments)
 }
+
+// FindBatchPayments will get all the batch payments
+func FindBatchPayments(cl *http.Client) ([]BatchPayment, error) {
+	return FindBatchPaymentsContext(context.Background(), cl)
+}
+
+// FindBatchPaymentsContext is the same as FindBatchPayments but the given context is used for the request
+func FindBatchPaymentsContext(ctx context.Context, cl *http.Client) ([]BatchPayment, error) {
+	return defaultService(cl).BatchPayments().List(ctx)
+}
@@ -1,14 +1,15 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
-	"github.com/quickaco/xerosdk/helpers"
+	"github.com/quickaco/xerosdk/xerotime"
 )
 
 const (
-	brandingThemeURL = "https://api.xero.com/api.xro/2.0/BrandingThemes"
+	brandingThemePath = "BrandingThemes"
 )
 
 //BrandingTheme applies structure and visuals to an invoice when printed or sent
@@ -24,7 +25,14 @@ type BrandingTheme struct {
 	SortOrder float64 `json:"SortOrder,omitempty" xml:"SortOrder,omitempty"`
 
 	// UTC timestamp of creation date of branding theme
-	CreatedDateUTC string `json:"CreatedDateUTC,omitempty" xml:"CreatedDateUTC,omitempty"`
+	CreatedDateUTC xerotime.DateTime `json:"CreatedDateUTC,omitempty" xml:"CreatedDateUTC,omitempty"`
+}
+
+// MarshalJSON method will leave out the zero dates and amounts, see
+// marshalOmitZero
+func (b BrandingTheme) MarshalJSON() ([]byte, error) {
+	type brandingTheme BrandingTheme
+	return marshalOmitZero(brandingTheme(b))
 }
 
 func unmarshalBrandingTheme(brandingThemeBytes []byte) ([]BrandingTheme, error) {
@@ -35,22 +43,36 @@ func unmarshalBrandingTheme(brandingThemeBytes []byte) ([]BrandingTheme, error)
 	if err != nil {
 		return nil, err
 	}
-
-	for n := len(response.Themes) - 1; n >= 0; n-- {
-		response.Themes[n].CreatedDateUTC, err = helpers.DotNetJSONTimeToRFC3339(response.Themes[n].CreatedDateUTC, true)
-		if err != nil {
-			return nil, err
-		}
-	}
 	return response.Themes, nil
 }
 
-// FindBrandingThemes will get all BrandingThemes.
-func FindBrandingThemes(cl *http.Client) ([]BrandingTheme, error) {
-	brandingThemeBytes, err := helpers.Find(cl, brandingThemeURL, nil, nil)
+// BrandingThemeService gives access to the BrandingThemes endpoint
+type BrandingThemeService struct {
+	s *Service
+}
+
+// BrandingThemes method will return the service for the BrandingThemes
+// endpoint
+func (s *Service) BrandingThemes() *BrandingThemeService {
+	return &BrandingThemeService{s: s}
+}
+
+// List method will get all the BrandingThemes
+func (bs *BrandingThemeService) List(ctx context.Context) ([]BrandingTheme, error) {
+	brandingThemeBytes, err := bs.s.find(withOperation(ctx, "BrandingThemes", "List"), bs.s.endpoint(brandingThemePath), nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	return unmarshalBrandingTheme(brandingThemeBytes)
 }
+
+// FindBrandingThemes will get all BrandingThemes.
+func FindBrandingThemes(cl *http.Client) ([]BrandingTheme, error) {
+	return FindBrandingThemesContext(context.Background(), cl)
+}
+
+// FindBrandingThemesContext is the same as FindBrandingThemes but the given context is used for the request
+func FindBrandingThemesContext(ctx context.Context, cl *http.Client) ([]BrandingTheme, error) {
+	return defaultService(cl).BrandingThemes().List(ctx)
+}
@@ -0,0 +1,329 @@
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/quickaco/xerosdk/helpers"
+)
+
+const (
+	// DefaultBulkChunkSize is the number of elements sent on each call of a
+	// bulk write, as recommended by Xero
+	DefaultBulkChunkSize = 50
+
+	// DefaultBulkMaxBytes is the largest body sent on each call of a bulk
+	// write, within the 3.5MB accepted by Xero
+	DefaultBulkMaxBytes = 3000000
+
+	// bulkBodyOverhead is the room kept in a body for the object wrapping
+	// the elements, such as {"Invoices":[]}
+	bulkBodyOverhead = 64
+)
+
+// BulkOptions keeps the criteria used to run a bulk write
+type BulkOptions struct {
+	// ChunkSize is the number of elements sent on each call, zero means
+	// DefaultBulkChunkSize
+	ChunkSize int
+
+	// MaxBytes is the largest JSON body of a call, a chunk that would be
+	// bigger is cut short. Zero means DefaultBulkMaxBytes
+	MaxBytes int
+
+	// Concurrency is the number of calls made at the same time, zero means
+	// one. Xero allows 5 concurrent calls per tenant, use a client built with
+	// a throttle to stay within its limits
+	Concurrency int
+
+	// Resume is the checkpoint of a previous run with the same elements, the
+	// chunks done by it are not sent again
+	Resume *BulkCheckpoint
+
+	// OnChunk is called after each chunk with its outcome and the checkpoint
+	// to store for resuming the run later. The calls are never concurrent
+	OnChunk func(chunk BulkChunk, checkpoint BulkCheckpoint)
+}
+
+// BulkChunk describes a chunk of a bulk write, the elements from Start to End
+// of the input. Err is set when the call failed as a whole. Meta is the
+// ResponseMeta of the call, the chunks don't fill the one of the context
+type BulkChunk struct {
+	Index int
+	Start int
+	End   int
+	Err   error
+	Meta  helpers.ResponseMeta
+}
+
+// BulkCheckpoint keeps the chunks done by a bulk write, it can be stored as
+// JSON and given back in BulkOptions.Resume
+type BulkCheckpoint struct {
+	ChunkSize int
+	MaxBytes  int
+	Done      []int
+}
+
+func (c *BulkCheckpoint) done(chunk int) bool {
+	if c == nil {
+		return false
+	}
+	for _, done := range c.Done {
+		if done == chunk {
+			return true
+		}
+	}
+	return false
+}
+
+// elementSizes will return the size of the JSON of each of the n elements
+func elementSizes(n int, element func(index int) interface{}) ([]int, error) {
+	sizes := make([]int, n)
+	for index := range sizes {
+		buf, err := json.Marshal(element(index))
+		if err != nil {
+			return nil, err
+		}
+		sizes[index] = len(buf)
+	}
+	return sizes, nil
+}
+
+// bulkChunks will split the elements with the given JSON sizes in chunks of
+// at most size elements whose body stays within maxBytes. An element bigger
+// than maxBytes is sent alone
+func bulkChunks(sizes []int, size int, maxBytes int) []BulkChunk {
+	var chunks []BulkChunk
+	start, bytes := 0, bulkBodyOverhead
+	for index, elementSize := range sizes {
+		if index > start && (index-start == size || bytes+elementSize+1 > maxBytes) {
+			chunks = append(chunks, BulkChunk{Index: len(chunks), Start: start, End: index})
+			start, bytes = index, bulkBodyOverhead
+		}
+		bytes += elementSize + 1
+	}
+	if start < len(sizes) {
+		chunks = append(chunks, BulkChunk{Index: len(chunks), Start: start, End: len(sizes)})
+	}
+	return chunks
+}
+
+// chunkContext will return the context of a chunk call, with its own
+// ResponseMeta and, when the context carries an idempotency key, its own key
+// made of that key and the chunk index, so a resumed run sends the same keys
+func chunkContext(ctx context.Context, chunk *BulkChunk) context.Context {
+	if key := helpers.IdempotencyKey(ctx); key != "" {
+		ctx = helpers.WithIdempotencyKey(ctx, fmt.Sprintf("%s-%d", key, chunk.Index))
+	}
+	return helpers.WithResponseMeta(ctx, &chunk.Meta)
+}
+
+// runBulk will split the elements with the given JSON sizes in chunks and
+// call send for each chunk not done yet, with at most opts.Concurrency calls
+// at the same time. mark is called for the elements that end up without a
+// result from send
+func runBulk(ctx context.Context, sizes []int, opts BulkOptions, send func(ctx context.Context, start, end int) error, mark func(start, end int, status string, err error)) error {
+	size, maxBytes := opts.ChunkSize, opts.MaxBytes
+	if opts.Resume != nil && opts.Resume.ChunkSize != 0 {
+		if size != 0 && size != opts.Resume.ChunkSize {
+			return fmt.Errorf("accounting: the checkpoint was taken with chunks of %d elements, not %d", opts.Resume.ChunkSize, size)
+		}
+		size = opts.Resume.ChunkSize
+	}
+	if opts.Resume != nil && opts.Resume.MaxBytes != 0 {
+		if maxBytes != 0 && maxBytes != opts.Resume.MaxBytes {
+			return fmt.Errorf("accounting: the checkpoint was taken with chunks of %d bytes, not %d", opts.Resume.MaxBytes, maxBytes)
+		}
+		maxBytes = opts.Resume.MaxBytes
+	}
+	if size <= 0 {
+		size = DefaultBulkChunkSize
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultBulkMaxBytes
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var (
+		mu         sync.Mutex
+		checkpoint = BulkCheckpoint{ChunkSize: size, MaxBytes: maxBytes}
+	)
+	if opts.Resume != nil {
+		checkpoint.Done = append(checkpoint.Done, opts.Resume.Done...)
+	}
+
+	queue := make(chan BulkChunk)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := range queue {
+				chunk.Err = send(chunkContext(ctx, &chunk), chunk.Start, chunk.End)
+				if chunk.Err != nil {
+					mark(chunk.Start, chunk.End, "", chunk.Err)
+				}
+
+				mu.Lock()
+				if chunk.Err == nil {
+					checkpoint.Done = append(checkpoint.Done, chunk.Index)
+					sort.Ints(checkpoint.Done)
+				}
+				if opts.OnChunk != nil {
+					snapshot := BulkCheckpoint{ChunkSize: size, MaxBytes: maxBytes, Done: append([]int(nil), checkpoint.Done...)}
+					opts.OnChunk(chunk, snapshot)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var err error
+	for _, chunk := range bulkChunks(sizes, size, maxBytes) {
+		if opts.Resume.done(chunk.Index) {
+			mark(chunk.Start, chunk.End, BatchStatusSkipped, nil)
+			continue
+		}
+		if err == nil {
+			select {
+			case queue <- chunk:
+				continue
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+		}
+		mark(chunk.Start, chunk.End, "", err)
+	}
+	close(queue)
+	wg.Wait()
+	return err
+}
+
+// BulkCreate method will create the given invoices in chunks, returning a
+// result per invoice in the same order. The error is only set when the run
+// couldn't be completed, the failed chunks are reported on the results
+func (is *InvoiceService) BulkCreate(ctx context.Context, invoices []Invoice, opts BulkOptions) ([]InvoiceResult, error) {
+	return is.bulk(ctx, invoices, opts, is.CreateBatch)
+}
+
+// BulkUpdate method will update the given invoices in chunks, see BulkCreate
+func (is *InvoiceService) BulkUpdate(ctx context.Context, invoices []Invoice, opts BulkOptions) ([]InvoiceResult, error) {
+	return is.bulk(ctx, invoices, opts, is.UpdateBatch)
+}
+
+func (is *InvoiceService) bulk(ctx context.Context, invoices []Invoice, opts BulkOptions, batch func(ctx context.Context, i *Invoices) ([]InvoiceResult, error)) ([]InvoiceResult, error) {
+	sizes, err := elementSizes(len(invoices), func(index int) interface{} { return invoices[index] })
+	if err != nil {
+		return nil, err
+	}
+	results := make([]InvoiceResult, len(invoices))
+	for index := range results {
+		results[index] = InvoiceResult{BatchResult: BatchResult{Index: index}, Invoice: invoices[index]}
+	}
+	mark := func(start, end int, status string, err error) {
+		for index := start; index < end; index++ {
+			results[index].Status, results[index].Err = status, err
+		}
+	}
+	err = runBulk(ctx, sizes, opts, func(ctx context.Context, start, end int) error {
+		chunk, err := batch(ctx, &Invoices{Invoices: invoices[start:end]})
+		if err != nil {
+			return err
+		}
+		for _, result := range chunk {
+			index := start + result.Index
+			result.Index = index
+			results[index] = result
+		}
+		return nil
+	}, mark)
+	return results, err
+}
+
+// BulkCreate method will create the given contacts in chunks, returning a
+// result per contact in the same order. The error is only set when the run
+// couldn't be completed, the failed chunks are reported on the results
+func (cs *ContactService) BulkCreate(ctx context.Context, contacts []Contact, opts BulkOptions) ([]ContactResult, error) {
+	return cs.bulk(ctx, contacts, opts, cs.CreateBatch)
+}
+
+// BulkUpdate method will update the given contacts in chunks, see BulkCreate
+func (cs *ContactService) BulkUpdate(ctx context.Context, contacts []Contact, opts BulkOptions) ([]ContactResult, error) {
+	return cs.bulk(ctx, contacts, opts, cs.UpdateBatch)
+}
+
+func (cs *ContactService) bulk(ctx context.Context, contacts []Contact, opts BulkOptions, batch func(ctx context.Context, c *Contacts) ([]ContactResult, error)) ([]ContactResult, error) {
+	sizes, err := elementSizes(len(contacts), func(index int) interface{} { return contacts[index] })
+	if err != nil {
+		return nil, err
+	}
+	results := make([]ContactResult, len(contacts))
+	for index := range results {
+		results[index] = ContactResult{BatchResult: BatchResult{Index: index}, Contact: contacts[index]}
+	}
+	mark := func(start, end int, status string, err error) {
+		for index := start; index < end; index++ {
+			results[index].Status, results[index].Err = status, err
+		}
+	}
+	err = runBulk(ctx, sizes, opts, func(ctx context.Context, start, end int) error {
+		chunk, err := batch(ctx, &Contacts{Contacts: contacts[start:end]})
+		if err != nil {
+			return err
+		}
+		for _, result := range chunk {
+			index := start + result.Index
+			result.Index = index
+			results[index] = result
+		}
+		return nil
+	}, mark)
+	return results, err
+}
+
+// BulkCreate method will create the given items in chunks, returning a result
+// per item in the same order. The error is only set when the run couldn't be
+// completed, the failed chunks are reported on the results
+func (is *ItemService) BulkCreate(ctx context.Context, items []Item, opts BulkOptions) ([]ItemResult, error) {
+	return is.bulk(ctx, items, opts, is.CreateBatch)
+}
+
+// BulkUpdate method will update the given items in chunks, see BulkCreate
+func (is *ItemService) BulkUpdate(ctx context.Context, items []Item, opts BulkOptions) ([]ItemResult, error) {
+	return is.bulk(ctx, items, opts, is.UpdateBatch)
+}
+
+func (is *ItemService) bulk(ctx context.Context, items []Item, opts BulkOptions, batch func(ctx context.Context, i *Items) ([]ItemResult, error)) ([]ItemResult, error) {
+	sizes, err := elementSizes(len(items), func(index int) interface{} { return items[index] })
+	if err != nil {
+		return nil, err
+	}
+	results := make([]ItemResult, len(items))
+	for index := range results {
+		results[index] = ItemResult{BatchResult: BatchResult{Index: index}, Item: items[index]}
+	}
+	mark := func(start, end int, status string, err error) {
+		for index := start; index < end; index++ {
+			results[index].Status, results[index].Err = status, err
+		}
+	}
+	err = runBulk(ctx, sizes, opts, func(ctx context.Context, start, end int) error {
+		chunk, err := batch(ctx, &Items{Items: items[start:end]})
+		if err != nil {
+			return err
+		}
+		for _, result := range chunk {
+			index := start + result.Index
+			result.Index = index
+			results[index] = result
+		}
+		return nil
+	}, mark)
+	return results, err
+}
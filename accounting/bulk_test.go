@@ -0,0 +1,134 @@
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/quickaco/xerosdk/helpers"
+)
+
+func namedContacts(n int) []Contact {
+	contacts := make([]Contact, n)
+	for i := range contacts {
+		contacts[i].Name = "Contact " + strconv.Itoa(i)
+	}
+	return contacts
+}
+
+func TestBulkCreateDryRun(t *testing.T) {
+	plan := helpers.NewPlan()
+	cl := &http.Client{Transport: helpers.NewDryRunTransport(nil, plan)}
+	service := NewService(cl, "https://api.xero.com/api.xro/2.0/")
+
+	results, err := service.Contacts().BulkCreate(context.Background(), namedContacts(7), BulkOptions{ChunkSize: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 7 {
+		t.Fatalf("results = %d, want 7", len(results))
+	}
+	for index, result := range results {
+		if !result.OK() || result.Index != index || result.Contact.Name != "Contact "+strconv.Itoa(index) {
+			t.Errorf("result %d = %+v, want the echoed contact OK", index, result.BatchResult)
+		}
+	}
+	if writes := plan.Writes(); len(writes) != 3 {
+		t.Errorf("plan = %d writes, want 3 chunks", len(writes))
+	}
+}
+
+// bulkServer answers each contact of a batch with the given status, and
+// leaves the contact at index drop out of each response when it's not -1
+func bulkServer(status string, drop int) (*httptest.Server, *[]string) {
+	var mu sync.Mutex
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request Contacts
+		json.NewDecoder(r.Body).Decode(&request)
+		mu.Lock()
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		mu.Unlock()
+		var response Contacts
+		for index, contact := range request.Contacts {
+			if index == drop {
+				continue
+			}
+			contact.StatusAttributeString = status
+			response.Contacts = append(response.Contacts, contact)
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	return server, &keys
+}
+
+func TestBulkCreateResultCount(t *testing.T) {
+	server, _ := bulkServer(BatchStatusOK, 1)
+	defer server.Close()
+	service := NewService(server.Client(), server.URL)
+
+	results, err := service.Contacts().BulkCreate(context.Background(), namedContacts(4), BulkOptions{ChunkSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for index, result := range results {
+		if result.OK() || !errors.Is(result.Err, ErrResultCount) {
+			t.Errorf("result %d = %+v, want ErrResultCount", index, result.BatchResult)
+		}
+	}
+}
+
+func TestBulkCreateStatuses(t *testing.T) {
+	server, keys := bulkServer(BatchStatusError, -1)
+	defer server.Close()
+	service := NewService(server.Client(), server.URL)
+
+	ctx := helpers.WithIdempotencyKey(context.Background(), "import")
+	results, err := service.Contacts().BulkCreate(ctx, namedContacts(4), BulkOptions{ChunkSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for index, result := range results {
+		if result.OK() || result.Status != BatchStatusError || result.Err != nil {
+			t.Errorf("result %d = %+v, want the ERROR status", index, result.BatchResult)
+		}
+	}
+	if len(*keys) != 2 || (*keys)[0] == (*keys)[1] {
+		t.Errorf("idempotency keys = %v, want one per chunk", *keys)
+	}
+}
+
+func TestBulkCreateResume(t *testing.T) {
+	server, keys := bulkServer(BatchStatusOK, -1)
+	defer server.Close()
+	service := NewService(server.Client(), server.URL)
+
+	var checkpoint BulkCheckpoint
+	results, err := service.Contacts().BulkCreate(context.Background(), namedContacts(5), BulkOptions{
+		ChunkSize: 2,
+		Resume:    &BulkCheckpoint{ChunkSize: 2, Done: []int{0, 2}},
+		OnChunk: func(chunk BulkChunk, c BulkCheckpoint) {
+			checkpoint = c
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*keys) != 1 {
+		t.Errorf("calls = %d, want only the chunk not done", len(*keys))
+	}
+	for index, result := range results {
+		wantOK := index == 2 || index == 3
+		if result.OK() != wantOK || (!wantOK && result.Status != BatchStatusSkipped) {
+			t.Errorf("result %d = %+v, want OK %v", index, result.BatchResult, wantOK)
+		}
+	}
+	if len(checkpoint.Done) != 3 {
+		t.Errorf("checkpoint = %+v, want the 3 chunks done", checkpoint)
+	}
+}
@@ -1,15 +1,19 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/decimal"
 	"github.com/quickaco/xerosdk/helpers"
+	"github.com/quickaco/xerosdk/xerotime"
 )
 
 const (
-	contactsURL = "https://api.xero.com/api.xro/2.0/Contacts"
+	contactsPath = "Contacts"
 )
 
 //Contact is a debtor/customer or creditor/supplier in a Xero Organisation
@@ -94,7 +98,7 @@ type Contact struct {
 	TrackingCategoryOption string `json:"TrackingCategoryOption,omitempty"`
 
 	// UTC timestamp of last update to contact
-	UpdatedDateUTC string `json:"UpdatedDateUTC,omitempty"`
+	UpdatedDateUTC xerotime.DateTime `json:"UpdatedDateUTC,omitempty"`
 
 	// Displays which contact groups a contact is included in
 	ContactGroups *[]ContactGroup `json:"ContactGroups,omitempty"`
@@ -106,13 +110,28 @@ type Contact struct {
 	BatchPayments BatchPayment `json:"BatchPayments,omitempty"`
 
 	// The default discount rate for the contact (read only)
-	Discount float64 `json:"Discount,omitempty"`
+	Discount decimal.Decimal `json:"Discount,omitempty"`
 
 	// The raw AccountsReceivable(sales Contacts) and AccountsPayable(bills) outstanding and overdue amounts, not converted to base currency (read only)
 	Balances Balances `json:"Balances,omitempty"`
 
 	// A boolean to indicate if a contact has an attachment
 	HasAttachments bool `json:"HasAttachments,omitempty"`
+
+	// Status of the element when it's sent with summarizeErrors=false, OK or
+	// ERROR
+	StatusAttributeString string `json:"StatusAttributeString,omitempty"`
+
+	// Validation errors of the element when it's sent with
+	// summarizeErrors=false
+	ValidationErrors []helpers.ValidationError `json:"ValidationErrors,omitempty"`
+}
+
+// MarshalJSON method will leave out the zero dates and amounts, see
+// marshalOmitZero
+func (c Contact) MarshalJSON() ([]byte, error) {
+	type contact Contact
+	return marshalOmitZero(contact(c))
 }
 
 //Contacts contains a collection of Contacts
@@ -130,27 +149,16 @@ type Balances struct {
 //Balance is the raw AccountsReceivable(sales invoices) and AccountsPayable(bills)
 //outstanding and overdue amounts, not converted to base currency
 type Balance struct {
-	Outstanding float64 `json:"Outstanding,omitempty"`
-	Overdue     float64 `json:"Overdue,omitempty"`
-}
-
-//The Xero API returns Dates based on the .Net JSON date format available at the time of development
-//We need to convert these to a more usable format - RFC3339 for consistency with what the API expects to recieve
-func (c *Contacts) convertDates() error {
-	var err error
-	for n := len(c.Contacts) - 1; n >= 0; n-- {
-		c.Contacts[n].UpdatedDateUTC, err = helpers.DotNetJSONTimeToRFC3339(c.Contacts[n].UpdatedDateUTC, true)
-		if err != nil {
-			return err
-		}
-	}
+	Outstanding decimal.Decimal `json:"Outstanding,omitempty"`
+	Overdue     decimal.Decimal `json:"Overdue,omitempty"`
+}
 
-	return nil
+// MarshalJSON method will leave out the zero amounts, see marshalOmitZero
+func (b Balance) MarshalJSON() ([]byte, error) {
+	type balance Balance
+	return marshalOmitZero(balance(b))
 }
 
-// unmarshalContact intermediate function used for apply the the changes in dates
-// format
-// TODO we can improve that overring the method Unmarshal
 func unmarshalContact(contactResponseBytes []byte) (*Contacts, error) {
 	var contactResponse *Contacts
 	err := json.Unmarshal(contactResponseBytes, &contactResponse)
@@ -158,27 +166,42 @@ func unmarshalContact(contactResponseBytes []byte) (*Contacts, error) {
 		return nil, err
 	}
 
-	err = contactResponse.convertDates()
-	if err != nil {
-		return nil, err
-	}
+	return contactResponse, nil
+}
 
-	return contactResponse, err
+// ContactService gives access to the Contacts endpoint
+type ContactService struct {
+	s *Service
 }
 
-// FindContacts will get all the contacts from Xero linked with the given
-// tenantID
-func FindContacts(cl *http.Client) (*Contacts, error) {
-	contactResponseBytes, err := helpers.Find(cl, contactsURL, nil, nil)
+// Contacts method will return the service for the Contacts endpoint
+func (s *Service) Contacts() *ContactService {
+	return &ContactService{s: s}
+}
+
+// List method will return the contacts linked with the tenantID, additional
+// querystringParameters such as where, page and order can be added as a map
+func (cs *ContactService) List(ctx context.Context, queryParameters map[string]string) (*Contacts, error) {
+	return cs.list(ctx, nil, queryParameters)
+}
+
+// ListModifiedSince method will return the contacts modified after the given
+// date
+func (cs *ContactService) ListModifiedSince(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*Contacts, error) {
+	return cs.list(ctx, modifiedSinceHeaders(modifiedSince), queryParameters)
+}
+
+func (cs *ContactService) list(ctx context.Context, additionalHeaders map[string]string, queryParameters map[string]string) (*Contacts, error) {
+	contactResponseBytes, err := cs.s.find(withOperation(ctx, "Contacts", "List"), cs.s.endpoint(contactsPath), additionalHeaders, queryParameters)
 	if err != nil {
 		return nil, err
 	}
 	return unmarshalContact(contactResponseBytes)
 }
 
-// FindContact will find the contact info with the given contactID
-func FindContact(cl *http.Client, contactID uuid.UUID) (*Contact, error) {
-	contactResponseBytes, err := helpers.Find(cl, contactsURL+"/"+contactID.String(), nil, nil)
+// Get method will return the contact with the given contactID
+func (cs *ContactService) Get(ctx context.Context, contactID uuid.UUID) (*Contact, error) {
+	contactResponseBytes, err := cs.s.find(withOperation(ctx, "Contacts", "Get"), cs.s.endpoint(contactsPath, contactID.String()), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -192,21 +215,21 @@ func FindContact(cl *http.Client, contactID uuid.UUID) (*Contact, error) {
 	return nil, nil
 }
 
-// Create will create contacts with the given information
-func (c *Contacts) Create(cl *http.Client) (*Contacts, error) {
+// Create method will create the given contacts
+func (cs *ContactService) Create(ctx context.Context, c *Contacts) (*Contacts, error) {
 	buf, err := json.Marshal(c)
 	if err != nil {
 		return nil, err
 	}
-	contactResponseBytes, err := helpers.Create(cl, contactsURL, buf)
+	contactResponseBytes, err := cs.s.create(withOperation(ctx, "Contacts", "Create"), cs.s.endpoint(contactsPath), buf)
 	if err != nil {
 		return nil, err
 	}
 	return unmarshalContact(contactResponseBytes)
 }
 
-// Update will update the contact with the given criteria
-func (c *Contact) Update(cl *http.Client) (*Contacts, error) {
+// Update method will update the given contact
+func (cs *ContactService) Update(ctx context.Context, c *Contact) (*Contacts, error) {
 	cn := Contacts{
 		Contacts: []Contact{*c},
 	}
@@ -214,9 +237,50 @@ func (c *Contact) Update(cl *http.Client) (*Contacts, error) {
 	if err != nil {
 		return nil, err
 	}
-	contactResponseBytes, err := helpers.Update(cl, contactsURL+"/"+c.ContactID, buf)
+	contactResponseBytes, err := cs.s.update(withOperation(ctx, "Contacts", "Update"), cs.s.endpoint(contactsPath, c.ContactID), buf)
 	if err != nil {
 		return nil, err
 	}
 	return unmarshalContact(contactResponseBytes)
 }
+
+// FindContacts will get all the contacts from Xero linked with the given
+// tenantID
+func FindContacts(cl *http.Client) (*Contacts, error) {
+	return FindContactsContext(context.Background(), cl)
+}
+
+// FindContactsContext is the same as FindContacts but the given context is used for the request
+func FindContactsContext(ctx context.Context, cl *http.Client) (*Contacts, error) {
+	return defaultService(cl).Contacts().List(ctx, nil)
+}
+
+// FindContact will find the contact info with the given contactID
+func FindContact(cl *http.Client, contactID uuid.UUID) (*Contact, error) {
+	return FindContactContext(context.Background(), cl, contactID)
+}
+
+// FindContactContext is the same as FindContact but the given context is used for the request
+func FindContactContext(ctx context.Context, cl *http.Client, contactID uuid.UUID) (*Contact, error) {
+	return defaultService(cl).Contacts().Get(ctx, contactID)
+}
+
+// Create will create contacts with the given information
+func (c *Contacts) Create(cl *http.Client) (*Contacts, error) {
+	return c.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the given context is used for the request
+func (c *Contacts) CreateContext(ctx context.Context, cl *http.Client) (*Contacts, error) {
+	return defaultService(cl).Contacts().Create(ctx, c)
+}
+
+// Update will update the contact with the given criteria
+func (c *Contact) Update(cl *http.Client) (*Contacts, error) {
+	return c.UpdateContext(context.Background(), cl)
+}
+
+// UpdateContext is the same as Update but the given context is used for the request
+func (c *Contact) UpdateContext(ctx context.Context, cl *http.Client) (*Contacts, error) {
+	return defaultService(cl).Contacts().Update(ctx, c)
+}
@@ -1,15 +1,15 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
 	"github.com/gofrs/uuid"
-	"github.com/quickaco/xerosdk/helpers"
 )
 
 const (
-	contactGroupsURL = "https://api.xero.com/api.xro/2.0/ContactGroups"
+	contactGroupsPath = "ContactGroups"
 )
 
 //ContactGroup is a way of organising Contacts into groups
@@ -43,9 +43,19 @@ func unmarshalContactGroup(contactGroupResponseBytes []byte) (*ContactGroups, er
 	return contactGroupResponse, err
 }
 
-// FindContactGroups will get all contactGroups
-func FindContactGroups(cl *http.Client) (*ContactGroups, error) {
-	contactGroupsBytes, err := helpers.Find(cl, contactGroupsURL, nil, nil)
+// ContactGroupService gives access to the ContactGroups endpoint
+type ContactGroupService struct {
+	s *Service
+}
+
+// ContactGroups method will return the service for the ContactGroups endpoint
+func (s *Service) ContactGroups() *ContactGroupService {
+	return &ContactGroupService{s: s}
+}
+
+// List method will get all the ContactGroups
+func (cs *ContactGroupService) List(ctx context.Context) (*ContactGroups, error) {
+	contactGroupsBytes, err := cs.s.find(withOperation(ctx, "ContactGroups", "List"), cs.s.endpoint(contactGroupsPath), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -53,9 +63,9 @@ func FindContactGroups(cl *http.Client) (*ContactGroups, error) {
 	return unmarshalContactGroup(contactGroupsBytes)
 }
 
-// FindContactGroup will get a single contactGroup - contactGroupID must be a GUID for an contactGroup
-func FindContactGroup(cl *http.Client, contactGroupID uuid.UUID) (*ContactGroups, error) {
-	contactGroupsBytes, err := helpers.Find(cl, contactGroupsURL+"/"+contactGroupID.String(), nil, nil)
+// Get method will get a single ContactGroup
+func (cs *ContactGroupService) Get(ctx context.Context, contactGroupID uuid.UUID) (*ContactGroups, error) {
+	contactGroupsBytes, err := cs.s.find(withOperation(ctx, "ContactGroups", "Get"), cs.s.endpoint(contactGroupsPath, contactGroupID.String()), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -63,9 +73,9 @@ func FindContactGroup(cl *http.Client, contactGroupID uuid.UUID) (*ContactGroups
 	return unmarshalContactGroup(contactGroupsBytes)
 }
 
-// RemoveContactGroup will get a single contactGroup - contactGroupID must be a GUID for an contactGroup
-func RemoveContactGroup(cl *http.Client, contactGroupID uuid.UUID) (*ContactGroups, error) {
-	contactGroupsBytes, err := helpers.Remove(cl, contactGroupsURL+"/"+contactGroupID.String())
+// Remove method will delete a single ContactGroup
+func (cs *ContactGroupService) Remove(ctx context.Context, contactGroupID uuid.UUID) (*ContactGroups, error) {
+	contactGroupsBytes, err := cs.s.remove(withOperation(ctx, "ContactGroups", "Remove"), cs.s.endpoint(contactGroupsPath, contactGroupID.String()))
 	if err != nil {
 		return nil, err
 	}
@@ -73,13 +83,13 @@ func RemoveContactGroup(cl *http.Client, contactGroupID uuid.UUID) (*ContactGrou
 	return unmarshalContactGroup(contactGroupsBytes)
 }
 
-//Create will create contactGroups given an ContactGroups struct
-func (c *ContactGroups) Create(cl *http.Client) (*ContactGroups, error) {
+// Create method will create the given ContactGroups
+func (cs *ContactGroupService) Create(ctx context.Context, c *ContactGroups) (*ContactGroups, error) {
 	buf, err := json.Marshal(c)
 	if err != nil {
 		return nil, err
 	}
-	contactGroupBytes, err := helpers.Create(cl, contactGroupsURL, buf)
+	contactGroupBytes, err := cs.s.create(withOperation(ctx, "ContactGroups", "Create"), cs.s.endpoint(contactGroupsPath), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -87,9 +97,8 @@ func (c *ContactGroups) Create(cl *http.Client) (*ContactGroups, error) {
 	return unmarshalContactGroup(contactGroupBytes)
 }
 
-//Update will update an contactGroup given an ContactGroups struct
-//This will only handle single contactGroup - you cannot update multiple contactGroups in a single call
-func (c *ContactGroup) Update(cl *http.Client) (*ContactGroups, error) {
+// Update method will update the given ContactGroup
+func (cs *ContactGroupService) Update(ctx context.Context, c *ContactGroup) (*ContactGroups, error) {
 	cg := ContactGroups{
 		ContactGroups: []ContactGroup{*c},
 	}
@@ -97,10 +106,61 @@ func (c *ContactGroup) Update(cl *http.Client) (*ContactGroups, error) {
 	if err != nil {
 		return nil, err
 	}
-	contactGroupBytes, err := helpers.Update(cl, contactGroupsURL+"/"+c.ContactGroupID, buf)
+	contactGroupBytes, err := cs.s.update(withOperation(ctx, "ContactGroups", "Update"), cs.s.endpoint(contactGroupsPath, c.ContactGroupID), buf)
 	if err != nil {
 		return nil, err
 	}
 
 	return unmarshalContactGroup(contactGroupBytes)
 }
+
+// FindContactGroups will get all contactGroups
+func FindContactGroups(cl *http.Client) (*ContactGroups, error) {
+	return FindContactGroupsContext(context.Background(), cl)
+}
+
+// FindContactGroupsContext is the same as FindContactGroups but the given context is used for the request
+func FindContactGroupsContext(ctx context.Context, cl *http.Client) (*ContactGroups, error) {
+	return defaultService(cl).ContactGroups().List(ctx)
+}
+
+// FindContactGroup will get a single contactGroup - contactGroupID must be a GUID for an contactGroup
+func FindContactGroup(cl *http.Client, contactGroupID uuid.UUID) (*ContactGroups, error) {
+	return FindContactGroupContext(context.Background(), cl, contactGroupID)
+}
+
+// FindContactGroupContext is the same as FindContactGroup but the given context is used for the request
+func FindContactGroupContext(ctx context.Context, cl *http.Client, contactGroupID uuid.UUID) (*ContactGroups, error) {
+	return defaultService(cl).ContactGroups().Get(ctx, contactGroupID)
+}
+
+// RemoveContactGroup will get a single contactGroup - contactGroupID must be a GUID for an contactGroup
+func RemoveContactGroup(cl *http.Client, contactGroupID uuid.UUID) (*ContactGroups, error) {
+	return RemoveContactGroupContext(context.Background(), cl, contactGroupID)
+}
+
+// RemoveContactGroupContext is the same as RemoveContactGroup but the given context is used for the request
+func RemoveContactGroupContext(ctx context.Context, cl *http.Client, contactGroupID uuid.UUID) (*ContactGroups, error) {
+	return defaultService(cl).ContactGroups().Remove(ctx, contactGroupID)
+}
+
+//Create will create contactGroups given an ContactGroups struct
+func (c *ContactGroups) Create(cl *http.Client) (*ContactGroups, error) {
+	return c.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the given context is used for the request
+func (c *ContactGroups) CreateContext(ctx context.Context, cl *http.Client) (*ContactGroups, error) {
+	return defaultService(cl).ContactGroups().Create(ctx, c)
+}
+
+//Update will update an contactGroup given an ContactGroups struct
+//This will only handle single contactGroup - you cannot update multiple contactGroups in a single call
+func (c *ContactGroup) Update(cl *http.Client) (*ContactGroups, error) {
+	return c.UpdateContext(context.Background(), cl)
+}
+
+// UpdateContext is the same as Update but the given context is used for the request
+func (c *ContactGroup) UpdateContext(ctx context.Context, cl *http.Client) (*ContactGroups, error) {
+	return defaultService(cl).ContactGroups().Update(ctx, c)
+}
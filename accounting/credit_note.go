@@ -1,16 +1,19 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
 
 	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/decimal"
 	"github.com/quickaco/xerosdk/helpers"
+	"github.com/quickaco/xerosdk/xerotime"
 )
 
 const (
-	creditNotesURL = "https://api.xero.com/api.xro/2.0/CreditNotes"
+	creditNotesPath = "CreditNotes"
 )
 
 //CreditNote an be raised directly against a customer or supplier,
@@ -26,7 +29,7 @@ type CreditNote struct {
 	// The date the credit note is issued YYYY-MM-DD.
 	// If the Date element is not specified then it will default
 	// to the current date based on the timezone setting of the organisation
-	Date string `json:"DateString,omitempty"`
+	Date xerotime.Date `json:"DateString,omitempty"`
 
 	// See Credit Note Status Codes
 	Status string `json:"Status,omitempty"`
@@ -38,22 +41,22 @@ type CreditNote struct {
 	LineItems []LineItem `json:"LineItems,omitempty"`
 
 	// The subtotal of the credit note excluding taxes
-	SubTotal float64 `json:"SubTotal,omitempty"`
+	SubTotal decimal.Decimal `json:"SubTotal,omitempty"`
 
 	// The total tax on the credit note
-	TotalTax float64 `json:"TotalTax,omitempty"`
+	TotalTax decimal.Decimal `json:"TotalTax,omitempty"`
 
 	// The total of the Credit Note(subtotal + total tax)
-	Total float64 `json:"Total,omitempty"`
+	Total decimal.Decimal `json:"Total,omitempty"`
 
 	// UTC timestamp of last update to the credit note
-	UpdatedDateUTC string `json:"UpdatedDateUTC,omitempty"`
+	UpdatedDateUTC xerotime.DateTime `json:"UpdatedDateUTC,omitempty"`
 
 	// Currency used for the Credit Note
 	CurrencyCode string `json:"CurrencyCode,omitempty"`
 
 	// Date when credit note was fully paid(UTC format)
-	FullyPaidOnDate string `json:"FullyPaidOnDate,omitempty"`
+	FullyPaidOnDate xerotime.Date `json:"FullyPaidOnDate,omitempty"`
 
 	// Xero generated unique identifier
 	CreditNoteID string `json:"CreditNoteID,omitempty"`
@@ -68,10 +71,10 @@ type CreditNote struct {
 	SentToContact bool `json:"SentToContact,omitempty"`
 
 	// The currency rate for a multicurrency invoice. If no rate is specified, the XE.com day rate is used
-	CurrencyRate float64 `json:"CurrencyRate,omitempty"`
+	CurrencyRate decimal.Decimal `json:"CurrencyRate,omitempty"`
 
 	// The remaining credit balance on the Credit Note
-	RemainingCredit float64 `json:"RemainingCredit,omitempty"`
+	RemainingCredit decimal.Decimal `json:"RemainingCredit,omitempty"`
 
 	// See Allocations
 	Allocations *[]Allocation `json:"Allocations,omitempty"`
@@ -81,6 +84,21 @@ type CreditNote struct {
 
 	// boolean to indicate if a credit note has an attachment
 	HasAttachments bool `json:"HasAttachments,omitempty"`
+
+	// Status of the element when it's sent with summarizeErrors=false, OK or
+	// ERROR
+	StatusAttributeString string `json:"StatusAttributeString,omitempty"`
+
+	// Validation errors of the element when it's sent with
+	// summarizeErrors=false
+	ValidationErrors []helpers.ValidationError `json:"ValidationErrors,omitempty"`
+}
+
+// MarshalJSON method will leave out the zero dates and amounts, see
+// marshalOmitZero
+func (c CreditNote) MarshalJSON() ([]byte, error) {
+	type creditNote CreditNote
+	return marshalOmitZero(creditNote(c))
 }
 
 //CreditNotes is a collection of CreditNote
@@ -88,42 +106,71 @@ type CreditNotes struct {
 	CreditNotes []CreditNote `json:"CreditNotes"`
 }
 
-//The Xero API returns Dates based on the .Net JSON date format available at the time of development
-//We need to convert these to a more usable format - RFC3339 for consistency with what the API expects to recieve
-func (c *CreditNotes) convertDates() error {
-	var err error
-	for n := len(c.CreditNotes) - 1; n >= 0; n-- {
-		c.CreditNotes[n].UpdatedDateUTC, err = helpers.DotNetJSONTimeToRFC3339(c.CreditNotes[n].UpdatedDateUTC, true)
-		if err != nil {
-			return err
-		}
+func unmarshalCreditNote(creditNoteResponseBytes []byte) (*CreditNotes, error) {
+	var creditNoteResponse *CreditNotes
+	err := json.Unmarshal(creditNoteResponseBytes, &creditNoteResponse)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return creditNoteResponse, nil
 }
 
-func unmarshalCreditNote(creditNoteResponseBytes []byte) (*CreditNotes, error) {
-	var creditNoteResponse *CreditNotes
-	err := json.Unmarshal(creditNoteResponseBytes, &creditNoteResponse)
+// CreditNoteService gives access to the CreditNotes endpoint
+type CreditNoteService struct {
+	s *Service
+}
+
+// CreditNotes method will return the service for the CreditNotes endpoint
+func (s *Service) CreditNotes() *CreditNoteService {
+	return &CreditNoteService{s: s}
+}
+
+// List method will get the CreditNotes, additional querystringParameters such
+// as where, page and order can be added as a map
+func (cs *CreditNoteService) List(ctx context.Context, queryParameters map[string]string) (*CreditNotes, error) {
+	return cs.list(ctx, nil, queryParameters)
+}
+
+// ListModifiedSince method will get the CreditNotes modified after the given
+// date
+func (cs *CreditNoteService) ListModifiedSince(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*CreditNotes, error) {
+	return cs.list(ctx, modifiedSinceHeaders(modifiedSince), queryParameters)
+}
+
+func (cs *CreditNoteService) list(ctx context.Context, additionalHeaders map[string]string, queryParameters map[string]string) (*CreditNotes, error) {
+	creditNotes, err := cs.s.find(withOperation(ctx, "CreditNotes", "List"), cs.s.endpoint(creditNotesPath), additionalHeaders, queryParameters)
 	if err != nil {
 		return nil, err
 	}
 
-	err = creditNoteResponse.convertDates()
+	return unmarshalCreditNote(creditNotes)
+}
+
+// Get method will get a single CreditNote
+func (cs *CreditNoteService) Get(ctx context.Context, creditNoteID uuid.UUID) (*CreditNote, error) {
+	creditNotes, err := cs.s.find(withOperation(ctx, "CreditNotes", "Get"), cs.s.endpoint(creditNotesPath, creditNoteID.String()), nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	return creditNoteResponse, err
+	notes, err := unmarshalCreditNote(creditNotes)
+	if err != nil {
+		return nil, err
+	}
+	if len(notes.CreditNotes) > 0 {
+		return &notes.CreditNotes[0], nil
+	}
+	return nil, nil
 }
 
-// Create will create accounts given an Accounts struct
-func (c *CreditNotes) Create(cl *http.Client) (*CreditNotes, error) {
+// Create method will create the given CreditNotes
+func (cs *CreditNoteService) Create(ctx context.Context, c *CreditNotes) (*CreditNotes, error) {
 	buf, err := json.Marshal(c)
 	if err != nil {
 		return nil, err
 	}
-	creditNotesBytes, err := helpers.Create(cl, creditNotesURL, buf)
+	creditNotesBytes, err := cs.s.create(withOperation(ctx, "CreditNotes", "Create"), cs.s.endpoint(creditNotesPath), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -131,9 +178,8 @@ func (c *CreditNotes) Create(cl *http.Client) (*CreditNotes, error) {
 	return unmarshalCreditNote(creditNotesBytes)
 }
 
-// Update will update an account given an Accounts struct
-// This will only handle single account - you cannot update multiple accounts in a single call
-func (c *CreditNote) Update(cl *http.Client) (*CreditNotes, error) {
+// Update method will update the given CreditNote
+func (cs *CreditNoteService) Update(ctx context.Context, c *CreditNote) (*CreditNotes, error) {
 	cn := CreditNotes{
 		CreditNotes: []CreditNote{*c},
 	}
@@ -141,7 +187,7 @@ func (c *CreditNote) Update(cl *http.Client) (*CreditNotes, error) {
 	if err != nil {
 		return nil, err
 	}
-	creditNotesBytes, err := helpers.Update(cl, creditNotesURL+"/"+c.CreditNoteID, buf)
+	creditNotesBytes, err := cs.s.update(withOperation(ctx, "CreditNotes", "Update"), cs.s.endpoint(creditNotesPath, c.CreditNoteID), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -149,16 +195,37 @@ func (c *CreditNote) Update(cl *http.Client) (*CreditNotes, error) {
 	return unmarshalCreditNote(creditNotesBytes)
 }
 
+// Create will create accounts given an Accounts struct
+func (c *CreditNotes) Create(cl *http.Client) (*CreditNotes, error) {
+	return c.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the given context is used for the request
+func (c *CreditNotes) CreateContext(ctx context.Context, cl *http.Client) (*CreditNotes, error) {
+	return defaultService(cl).CreditNotes().Create(ctx, c)
+}
+
+// Update will update an account given an Accounts struct
+// This will only handle single account - you cannot update multiple accounts in a single call
+func (c *CreditNote) Update(cl *http.Client) (*CreditNotes, error) {
+	return c.UpdateContext(context.Background(), cl)
+}
+
+// UpdateContext is the same as Update but the given context is used for the request
+func (c *CreditNote) UpdateContext(ctx context.Context, cl *http.Client) (*CreditNotes, error) {
+	return defaultService(cl).CreditNotes().Update(ctx, c)
+}
+
 // FindCreditNotes will get all CreditNotes. These Credit Notes will not have details like line items by default.
 // If you need details then then add a 'page' querystringParameter and get 100 Credit Notes at a time
 // additional querystringParameters such as where, page, order can be added as a map
 func FindCreditNotes(cl *http.Client, queryParameters map[string]string) (*CreditNotes, error) {
-	creditNotes, err := helpers.Find(cl, creditNotesURL, nil, queryParameters)
-	if err != nil {
-		return nil, err
-	}
+	return FindCreditNotesContext(context.Background(), cl, queryParameters)
+}
 
-	return unmarshalCreditNote(creditNotes)
+// FindCreditNotesContext is the same as FindCreditNotes but the given context is used for the request
+func FindCreditNotesContext(ctx context.Context, cl *http.Client, queryParameters map[string]string) (*CreditNotes, error) {
+	return defaultService(cl).CreditNotes().List(ctx, queryParameters)
 }
 
 // FindCreditNotesModifiedSince will get all Credit Notes modified after a specified date.
@@ -166,27 +233,20 @@ func FindCreditNotes(cl *http.Client, queryParameters map[string]string) (*Credi
 // If you need details then then add a 'page' querystringParameter and get 100 Credit Notes at a time
 // additional querystringParameters such as where, page, order can be added as a map
 func FindCreditNotesModifiedSince(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*CreditNotes, error) {
-	additionalHeaders := map[string]string{}
-	additionalHeaders["If-Modified-Since"] = modifiedSince.Format(time.RFC3339)
-
-	creditNotes, err := helpers.Find(cl, creditNotesURL, additionalHeaders, queryParameters)
-	if err != nil {
-		return nil, err
-	}
+	return FindCreditNotesModifiedSinceContext(context.Background(), cl, modifiedSince, queryParameters)
+}
 
-	return unmarshalCreditNote(creditNotes)
+// FindCreditNotesModifiedSinceContext is the same as FindCreditNotesModifiedSince but the given context is used for the request
+func FindCreditNotesModifiedSinceContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*CreditNotes, error) {
+	return defaultService(cl).CreditNotes().ListModifiedSince(ctx, modifiedSince, queryParameters)
 }
 
 // FindCreditNote will get a single creditNote - creditNoteID can be a GUID for a creditNote or a creditNote number
 func FindCreditNote(cl *http.Client, creditNoteID uuid.UUID) (*CreditNote, error) {
-	creditNotes, err := helpers.Find(cl, creditNotesURL+"/"+creditNoteID.String(), nil, nil)
-	if err != nil {
-		return nil, err
-	}
+	return FindCreditNoteContext(context.Background(), cl, creditNoteID)
+}
 
-	notes, err := unmarshalCreditNote(creditNotes)
-	if err != nil {
-		return nil, err
-	}
-	return &notes.CreditNotes[0], nil
+// FindCreditNoteContext is the same as FindCreditNote but the given context is used for the request
+func FindCreditNoteContext(ctx context.Context, cl *http.Client, creditNoteID uuid.UUID) (*CreditNote, error) {
+	return defaultService(cl).CreditNotes().Get(ctx, creditNoteID)
 }
@@ -1,14 +1,13 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
-
-	"github.com/quickaco/xerosdk/helpers"
 )
 
 const (
-	currencyURL = "https://api.xero.com/api.xro/2.0/Currencies"
+	currencyPath = "Currencies"
 )
 
 //Currency is the local currency set up to be used in Xero
@@ -36,9 +35,19 @@ func unmarshalCurrencies(currencyResponseBytes []byte) (*Currencies, error) {
 	return currencyResponse, err
 }
 
-// FindCurrencies will get all currencies
-func FindCurrencies(cl *http.Client) (*Currencies, error) {
-	currencyBytes, err := helpers.Find(cl, currencyURL, nil, nil)
+// CurrencyService gives access to the Currencies endpoint
+type CurrencyService struct {
+	s *Service
+}
+
+// Currencies method will return the service for the Currencies endpoint
+func (s *Service) Currencies() *CurrencyService {
+	return &CurrencyService{s: s}
+}
+
+// List method will get all the Currencies
+func (cs *CurrencyService) List(ctx context.Context) (*Currencies, error) {
+	currencyBytes, err := cs.s.find(withOperation(ctx, "Currencies", "List"), cs.s.endpoint(currencyPath), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -46,16 +55,36 @@ func FindCurrencies(cl *http.Client) (*Currencies, error) {
 	return unmarshalCurrencies(currencyBytes)
 }
 
-// Create will create a new currency on Xero
-func (c *Currencies) Create(cl *http.Client) (*Currencies, error) {
+// Create method will create the given Currencies
+func (cs *CurrencyService) Create(ctx context.Context, c *Currencies) (*Currencies, error) {
 	buf, err := json.Marshal(c)
 	if err != nil {
 		return nil, err
 	}
 
-	currencyBytes, err := helpers.Create(cl, currencyURL, buf)
+	currencyBytes, err := cs.s.create(withOperation(ctx, "Currencies", "Create"), cs.s.endpoint(currencyPath), buf)
 	if err != nil {
 		return nil, err
 	}
 	return unmarshalCurrencies(currencyBytes)
 }
+
+// FindCurrencies will get all currencies
+func FindCurrencies(cl *http.Client) (*Currencies, error) {
+	return FindCurrenciesContext(context.Background(), cl)
+}
+
+// FindCurrenciesContext is the same as FindCurrencies but the given context is used for the request
+func FindCurrenciesContext(ctx context.Context, cl *http.Client) (*Currencies, error) {
+	return defaultService(cl).Currencies().List(ctx)
+}
+
+// Create will create a new currency on Xero
+func (c *Currencies) Create(cl *http.Client) (*Currencies, error) {
+	return c.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the given context is used for the request
+func (c *Currencies) CreateContext(ctx context.Context, cl *http.Client) (*Currencies, error) {
+	return defaultService(cl).Currencies().Create(ctx, c)
+}
@@ -1,14 +1,13 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
-
-	"github.com/quickaco/xerosdk/helpers"
 )
 
 const (
-	employeeURL = "https://api.xero.com/api.xro/2.0/Employees"
+	employeePath = "Employees"
 )
 
 //Employee is for the deprecated Pay run feature.
@@ -35,9 +34,20 @@ type Employees struct {
 	Employess []Employee `json:"Employees,omitempty"`
 }
 
-// FindEmployees will find the info about employees
-func FindEmployees(cl *http.Client, queryParameters map[string]string) (em *Employees, err error) {
-	employeeResponseBytes, err := helpers.Find(cl, employeeURL, nil, queryParameters)
+// EmployeeService gives access to the Employees endpoint
+type EmployeeService struct {
+	s *Service
+}
+
+// Employees method will return the service for the Employees endpoint
+func (s *Service) Employees() *EmployeeService {
+	return &EmployeeService{s: s}
+}
+
+// List method will get the Employees, additional querystringParameters such
+// as where and order can be added as a map
+func (es *EmployeeService) List(ctx context.Context, queryParameters map[string]string) (em *Employees, err error) {
+	employeeResponseBytes, err := es.s.find(withOperation(ctx, "Employees", "List"), es.s.endpoint(employeePath), nil, queryParameters)
 	if err != nil {
 		return nil, err
 	}
@@ -48,13 +58,13 @@ func FindEmployees(cl *http.Client, queryParameters map[string]string) (em *Empl
 	return em, nil
 }
 
-// Create will create employees with the given information
-func (e *Employees) Create(cl *http.Client) (em *Employees, err error) {
+// Create method will create the given Employees
+func (es *EmployeeService) Create(ctx context.Context, e *Employees) (em *Employees, err error) {
 	buf, err := json.Marshal(e)
 	if err != nil {
 		return nil, err
 	}
-	employeeResponseBytes, err := helpers.Create(cl, employeeURL, buf)
+	employeeResponseBytes, err := es.s.create(withOperation(ctx, "Employees", "Create"), es.s.endpoint(employeePath), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -65,16 +75,16 @@ func (e *Employees) Create(cl *http.Client) (em *Employees, err error) {
 	return em, nil
 }
 
-// Update will update employees with the given criteria
-func (e *Employee) Update(cl *http.Client) (em *Employees, err error) {
-	es := Employees{
+// Update method will update the given Employee
+func (es *EmployeeService) Update(ctx context.Context, e *Employee) (em *Employees, err error) {
+	employees := Employees{
 		Employess: []Employee{*e},
 	}
-	buf, err := json.Marshal(es)
+	buf, err := json.Marshal(employees)
 	if err != nil {
 		return nil, err
 	}
-	employeeResponseBytes, err := helpers.Update(cl, employeeURL+"/"+e.EmployeeID, buf)
+	employeeResponseBytes, err := es.s.update(withOperation(ctx, "Employees", "Update"), es.s.endpoint(employeePath, e.EmployeeID), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -84,3 +94,33 @@ func (e *Employee) Update(cl *http.Client) (em *Employees, err error) {
 	}
 	return em, nil
 }
+
+// FindEmployees will find the info about employees
+func FindEmployees(cl *http.Client, queryParameters map[string]string) (em *Employees, err error) {
+	return FindEmployeesContext(context.Background(), cl, queryParameters)
+}
+
+// FindEmployeesContext is the same as FindEmployees but the given context is used for the request
+func FindEmployeesContext(ctx context.Context, cl *http.Client, queryParameters map[string]string) (em *Employees, err error) {
+	return defaultService(cl).Employees().List(ctx, queryParameters)
+}
+
+// Create will create employees with the given information
+func (e *Employees) Create(cl *http.Client) (em *Employees, err error) {
+	return e.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the given context is used for the request
+func (e *Employees) CreateContext(ctx context.Context, cl *http.Client) (em *Employees, err error) {
+	return defaultService(cl).Employees().Create(ctx, e)
+}
+
+// Update will update employees with the given criteria
+func (e *Employee) Update(cl *http.Client) (em *Employees, err error) {
+	return e.UpdateContext(context.Background(), cl)
+}
+
+// UpdateContext is the same as Update but the given context is used for the request
+func (e *Employee) UpdateContext(ctx context.Context, cl *http.Client) (em *Employees, err error) {
+	return defaultService(cl).Employees().Update(ctx, e)
+}
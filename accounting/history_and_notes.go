@@ -1,14 +1,15 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
-	"github.com/quickaco/xerosdk/helpers"
+	"github.com/quickaco/xerosdk/xerotime"
 )
 
 const (
-	historyRecordURL = "https://api.xero.com/api.xro/2.0/"
+	historyRecordPath = "history"
 )
 
 // HistoryRecord is a record of monies transferred from one bank account to another
@@ -18,7 +19,7 @@ type HistoryRecord struct {
 	Changes string `json:"Changes,omitempty"`
 
 	// UTC date that the history record was created
-	DateUTC string `json:"DateUTC,omitempty"`
+	DateUTC xerotime.DateTime `json:"DateUTC,omitempty"`
 
 	// The user responsible for the change ("System Generated" when the change happens via API)
 	User string `json:"User,omitempty"`
@@ -27,24 +28,18 @@ type HistoryRecord struct {
 	Details string `json:"Details"`
 }
 
+// MarshalJSON method will leave out the zero dates and amounts, see
+// marshalOmitZero
+func (h HistoryRecord) MarshalJSON() ([]byte, error) {
+	type historyRecord HistoryRecord
+	return marshalOmitZero(historyRecord(h))
+}
+
 // HistoryRecords contains a collection of BankTransfers
 type HistoryRecords struct {
 	HistoryRecords []HistoryRecord `json:"HistoryRecords"`
 }
 
-//The Xero API returns Dates based on the .Net JSON date format available at the time of development
-//We need to convert these to a more usable format - RFC3339 for consistency with what the API expects to recieve
-func (h *HistoryRecords) convertDates() error {
-	var err error
-	for n := len(h.HistoryRecords) - 1; n >= 0; n-- {
-		h.HistoryRecords[n].DateUTC, err = helpers.DotNetJSONTimeToRFC3339(h.HistoryRecords[n].DateUTC, false)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
 func unmarshalHistoryRecord(HistoryRecordResponseBytes []byte) (*HistoryRecords, error) {
 	var historyRecordResponse *HistoryRecords
 	err := json.Unmarshal(HistoryRecordResponseBytes, &historyRecordResponse)
@@ -52,18 +47,23 @@ func unmarshalHistoryRecord(HistoryRecordResponseBytes []byte) (*HistoryRecords,
 		return nil, err
 	}
 
-	err = historyRecordResponse.convertDates()
-	if err != nil {
-		return nil, err
-	}
+	return historyRecordResponse, nil
+}
 
-	return historyRecordResponse, err
+// HistoryService gives access to the history and notes of the documents
+type HistoryService struct {
+	s *Service
 }
 
-//FindHistoryAndNotes gets all history items and notes for a given type and ID.
-//it is not supported on all endpoints.  See https://developer.xero.com/documentation/api/history-and-notes#SupportedDocs
-func FindHistoryAndNotes(cl *http.Client, docType string, id string) (*HistoryRecords, error) {
-	historyAndNotesBytes, err := helpers.Find(cl, historyRecordURL+docType+"/"+id+"/history", nil, nil)
+// History method will return the service for the history and notes of the
+// documents
+func (s *Service) History() *HistoryService {
+	return &HistoryService{s: s}
+}
+
+// List method will get all history items and notes for a given type and ID
+func (hs *HistoryService) List(ctx context.Context, docType string, id string) (*HistoryRecords, error) {
+	historyAndNotesBytes, err := hs.s.find(withOperation(ctx, "History", "List"), hs.s.endpoint(docType, id, historyRecordPath), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -71,16 +71,37 @@ func FindHistoryAndNotes(cl *http.Client, docType string, id string) (*HistoryRe
 	return unmarshalHistoryRecord(historyAndNotesBytes)
 }
 
-// Create will create History Records given a HistoryRecords struct and a docType and id
-func (h *HistoryRecords) Create(cl *http.Client, docType string, id string) (*HistoryRecords, error) {
+// Create method will create the given History Records for a given type and ID
+func (hs *HistoryService) Create(ctx context.Context, docType string, id string, h *HistoryRecords) (*HistoryRecords, error) {
 	buf, err := json.Marshal(h)
 	if err != nil {
 		return nil, err
 	}
-	historyAndNotesBytes, err := helpers.Create(cl, historyRecordURL+docType+"/"+id+"/history", buf)
+	historyAndNotesBytes, err := hs.s.create(withOperation(ctx, "History", "Create"), hs.s.endpoint(docType, id, historyRecordPath), buf)
 	if err != nil {
 		return nil, err
 	}
 
 	return unmarshalHistoryRecord(historyAndNotesBytes)
 }
+
+//FindHistoryAndNotes gets all history items and notes for a given type and ID.
+//it is not supported on all endpoints.  See https://developer.xero.com/documentation/api/history-and-notes#SupportedDocs
+func FindHistoryAndNotes(cl *http.Client, docType string, id string) (*HistoryRecords, error) {
+	return FindHistoryAndNotesContext(context.Background(), cl, docType, id)
+}
+
+// FindHistoryAndNotesContext is the same as FindHistoryAndNotes but the given context is used for the request
+func FindHistoryAndNotesContext(ctx context.Context, cl *http.Client, docType string, id string) (*HistoryRecords, error) {
+	return defaultService(cl).History().List(ctx, docType, id)
+}
+
+// Create will create History Records given a HistoryRecords struct and a docType and id
+func (h *HistoryRecords) Create(cl *http.Client, docType string, id string) (*HistoryRecords, error) {
+	return h.CreateContext(context.Background(), cl, docType, id)
+}
+
+// CreateContext is the same as Create but the given context is used for the request
+func (h *HistoryRecords) CreateContext(ctx context.Context, cl *http.Client, docType string, id string) (*HistoryRecords, error) {
+	return defaultService(cl).History().Create(ctx, docType, id, h)
+}
@@ -1,15 +1,19 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/decimal"
 	"github.com/quickaco/xerosdk/helpers"
+	"github.com/quickaco/xerosdk/xerotime"
 )
 
 const (
-	invoiceURL = "https://api.xero.com/api.xro/2.0/Invoices"
+	invoicePath = "Invoices"
 )
 
 //Invoice is an Accounts Payable or Accounts Recievable document in a Xero organisation
@@ -24,10 +28,10 @@ type Invoice struct {
 	LineItems []LineItem `json:"LineItems"`
 
 	// Date invoice was issued – YYYY-MM-DD. If the Date element is not specified it will default to the current date based on the timezone setting of the organisation
-	Date string `json:"DateString,omitempty"`
+	Date xerotime.Date `json:"DateString,omitempty"`
 
 	// Date invoice is due – YYYY-MM-DD
-	DueDate string `json:"DueDateString,omitempty"`
+	DueDate xerotime.Date `json:"DueDateString,omitempty"`
 
 	// Line amounts are exclusive of tax by default if you don’t specify this element. See Line Amount Types
 	LineAmountTypes string `json:"LineAmountTypes,omitempty"`
@@ -48,7 +52,7 @@ type Invoice struct {
 	CurrencyCode string `json:"CurrencyCode,omitempty"`
 
 	// The currency rate for a multicurrency invoice. If no rate is specified, the XE.com day rate is used. (max length = [18].[6])
-	CurrencyRate float64 `json:"CurrencyRate,omitempty"`
+	CurrencyRate decimal.Decimal `json:"CurrencyRate,omitempty"`
 
 	// See Invoice Status Codes
 	Status string `json:"Status,omitempty"`
@@ -57,22 +61,22 @@ type Invoice struct {
 	SentToContact bool `json:"SentToContact,omitempty"`
 
 	// Shown on sales invoices (Accounts Receivable) when this has been set
-	ExpectedPaymentDate string `json:"ExpectedPaymentDate,omitempty"`
+	ExpectedPaymentDate xerotime.Date `json:"ExpectedPaymentDate,omitempty"`
 
 	// Shown on bills (Accounts Payable) when this has been set
-	PlannedPaymentDate string `json:"PlannedPaymentDate,omitempty"`
+	PlannedPaymentDate xerotime.Date `json:"PlannedPaymentDate,omitempty"`
 
 	// Total of invoice excluding taxes
-	SubTotal float64 `json:"SubTotal,omitempty"`
+	SubTotal decimal.Decimal `json:"SubTotal,omitempty"`
 
 	// Total tax on invoice
-	TotalTax float64 `json:"TotalTax,omitempty"`
+	TotalTax decimal.Decimal `json:"TotalTax,omitempty"`
 
 	// Total of Invoice tax inclusive (i.e. SubTotal + TotalTax). This will be ignored if it doesn’t equal the sum of the LineAmounts
-	Total float64 `json:"Total,omitempty"`
+	Total decimal.Decimal `json:"Total,omitempty"`
 
 	// Total of discounts applied on the invoice line items
-	TotalDiscount float64 `json:"TotalDiscount,omitempty"`
+	TotalDiscount decimal.Decimal `json:"TotalDiscount,omitempty"`
 
 	// Xero generated unique identifier for invoice
 	InvoiceID string `json:"InvoiceID,omitempty"`
@@ -90,22 +94,37 @@ type Invoice struct {
 	Overpayments *[]Overpayment `json:"Overpayments,omitempty"`
 
 	// Amount remaining to be paid on invoice
-	AmountDue float64 `json:"AmountDue,omitempty"`
+	AmountDue decimal.Decimal `json:"AmountDue,omitempty"`
 
 	// Sum of payments received for invoice
-	AmountPaid float64 `json:"AmountPaid,omitempty"`
+	AmountPaid decimal.Decimal `json:"AmountPaid,omitempty"`
 
 	// The date the invoice was fully paid. Only returned on fully paid invoices
-	FullyPaidOnDate string `json:"FullyPaidOnDate,omitempty"`
+	FullyPaidOnDate xerotime.Date `json:"FullyPaidOnDate,omitempty"`
 
 	// Sum of all credit notes, over-payments and pre-payments applied to invoice
-	AmountCredited float64 `json:"AmountCredited,omitempty"`
+	AmountCredited decimal.Decimal `json:"AmountCredited,omitempty"`
 
 	// Last modified date UTC format
-	UpdatedDateUTC string `json:"UpdatedDateUTC,omitempty"`
+	UpdatedDateUTC xerotime.DateTime `json:"UpdatedDateUTC,omitempty"`
 
 	// Details of credit notes that have been applied to an invoice
 	CreditNotes *[]CreditNote `json:"CreditNotes,omitempty"`
+
+	// Status of the element when it's sent with summarizeErrors=false, OK or
+	// ERROR
+	StatusAttributeString string `json:"StatusAttributeString,omitempty"`
+
+	// Validation errors of the element when it's sent with
+	// summarizeErrors=false
+	ValidationErrors []helpers.ValidationError `json:"ValidationErrors,omitempty"`
+}
+
+// MarshalJSON method will leave out the zero dates and amounts, see
+// marshalOmitZero
+func (i Invoice) MarshalJSON() ([]byte, error) {
+	type invoice Invoice
+	return marshalOmitZero(invoice(i))
 }
 
 //Invoices contains a collection of Invoices
@@ -113,20 +132,6 @@ type Invoices struct {
 	Invoices []Invoice `json:"Invoices"`
 }
 
-//The Xero API returns Dates based on the .Net JSON date format available at the time of development
-//We need to convert these to a more usable format - RFC3339 for consistency with what the API expects to recieve
-func (i *Invoices) convertDates() error {
-	var err error
-	for n := len(i.Invoices) - 1; n >= 0; n-- {
-		i.Invoices[n].UpdatedDateUTC, err = helpers.DotNetJSONTimeToRFC3339(i.Invoices[n].UpdatedDateUTC, true)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
 func unmarshalInvoice(invoiceResponseBytes []byte) (*Invoices, error) {
 	var invoiceResponse *Invoices
 	err := json.Unmarshal(invoiceResponseBytes, &invoiceResponse)
@@ -134,27 +139,42 @@ func unmarshalInvoice(invoiceResponseBytes []byte) (*Invoices, error) {
 		return nil, err
 	}
 
-	err = invoiceResponse.convertDates()
-	if err != nil {
-		return nil, err
-	}
+	return invoiceResponse, nil
+}
 
-	return invoiceResponse, err
+// InvoiceService gives access to the Invoices endpoint
+type InvoiceService struct {
+	s *Service
 }
 
-// FindInvoices function will return the list of all the invoices tied to this
-// tenantID
-func FindInvoices(cl *http.Client) (*Invoices, error) {
-	invoiceResponseBytes, err := helpers.Find(cl, invoiceURL, nil, nil)
+// Invoices method will return the service for the Invoices endpoint
+func (s *Service) Invoices() *InvoiceService {
+	return &InvoiceService{s: s}
+}
+
+// List method will return the invoices tied to the tenantID, additional
+// querystringParameters such as where, page and order can be added as a map
+func (is *InvoiceService) List(ctx context.Context, queryParameters map[string]string) (*Invoices, error) {
+	return is.list(ctx, nil, queryParameters)
+}
+
+// ListModifiedSince method will return the invoices modified after the given
+// date
+func (is *InvoiceService) ListModifiedSince(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*Invoices, error) {
+	return is.list(ctx, modifiedSinceHeaders(modifiedSince), queryParameters)
+}
+
+func (is *InvoiceService) list(ctx context.Context, additionalHeaders map[string]string, queryParameters map[string]string) (*Invoices, error) {
+	invoiceResponseBytes, err := is.s.find(withOperation(ctx, "Invoices", "List"), is.s.endpoint(invoicePath), additionalHeaders, queryParameters)
 	if err != nil {
 		return nil, err
 	}
 	return unmarshalInvoice(invoiceResponseBytes)
 }
 
-// FindInvoice function will return the invoice with the given criteria
-func FindInvoice(cl *http.Client, invoiceID uuid.UUID) (*Invoice, error) {
-	invoiceResponseBytes, err := helpers.Find(cl, invoiceURL+"/"+invoiceID.String(), nil, nil)
+// Get method will return the invoice with the given invoiceID
+func (is *InvoiceService) Get(ctx context.Context, invoiceID uuid.UUID) (*Invoice, error) {
+	invoiceResponseBytes, err := is.s.find(withOperation(ctx, "Invoices", "Get"), is.s.endpoint(invoicePath, invoiceID.String()), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -168,28 +188,69 @@ func FindInvoice(cl *http.Client, invoiceID uuid.UUID) (*Invoice, error) {
 	return nil, nil
 }
 
-// Create method will create a new invoice with the information given
-func (i *Invoices) Create(cl *http.Client) (*Invoices, error) {
+// Create method will create the given invoices
+func (is *InvoiceService) Create(ctx context.Context, i *Invoices) (*Invoices, error) {
 	buf, err := json.Marshal(i)
 	if err != nil {
 		return nil, err
 	}
-	invoiceResponseBytes, err := helpers.Create(cl, invoiceURL, buf)
+	invoiceResponseBytes, err := is.s.create(withOperation(ctx, "Invoices", "Create"), is.s.endpoint(invoicePath), buf)
 	if err != nil {
 		return nil, err
 	}
 	return unmarshalInvoice(invoiceResponseBytes)
 }
 
-// Update will update the information with the given invoice
-func (i *Invoice) Update(cl *http.Client) (*Invoices, error) {
+// Update method will update the given invoice
+func (is *InvoiceService) Update(ctx context.Context, i *Invoice) (*Invoices, error) {
 	buf, err := json.Marshal(i)
 	if err != nil {
 		return nil, err
 	}
-	invoiceResponseBytes, err := helpers.Update(cl, invoiceURL+"/"+i.InvoiceID, buf)
+	invoiceResponseBytes, err := is.s.update(withOperation(ctx, "Invoices", "Update"), is.s.endpoint(invoicePath, i.InvoiceID), buf)
 	if err != nil {
 		return nil, err
 	}
 	return unmarshalInvoice(invoiceResponseBytes)
 }
+
+// FindInvoices function will return the list of all the invoices tied to this
+// tenantID
+func FindInvoices(cl *http.Client) (*Invoices, error) {
+	return FindInvoicesContext(context.Background(), cl)
+}
+
+// FindInvoicesContext is the same as FindInvoices but the given context is used for the request
+func FindInvoicesContext(ctx context.Context, cl *http.Client) (*Invoices, error) {
+	return defaultService(cl).Invoices().List(ctx, nil)
+}
+
+// FindInvoice function will return the invoice with the given criteria
+func FindInvoice(cl *http.Client, invoiceID uuid.UUID) (*Invoice, error) {
+	return FindInvoiceContext(context.Background(), cl, invoiceID)
+}
+
+// FindInvoiceContext is the same as FindInvoice but the given context is used for the request
+func FindInvoiceContext(ctx context.Context, cl *http.Client, invoiceID uuid.UUID) (*Invoice, error) {
+	return defaultService(cl).Invoices().Get(ctx, invoiceID)
+}
+
+// Create method will create a new invoice with the information given
+func (i *Invoices) Create(cl *http.Client) (*Invoices, error) {
+	return i.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the given context is used for the request
+func (i *Invoices) CreateContext(ctx context.Context, cl *http.Client) (*Invoices, error) {
+	return defaultService(cl).Invoices().Create(ctx, i)
+}
+
+// Update will update the information with the given invoice
+func (i *Invoice) Update(cl *http.Client) (*Invoices, error) {
+	return i.UpdateContext(context.Background(), cl)
+}
+
+// UpdateContext is the same as Update but the given context is used for the request
+func (i *Invoice) UpdateContext(ctx context.Context, cl *http.Client) (*Invoices, error) {
+	return defaultService(cl).Invoices().Update(ctx, i)
+}
@@ -1,14 +1,13 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
-
-	"github.com/quickaco/xerosdk/helpers"
 )
 
 const (
-	invoiceRemindersURL = "https://api.xero.com/api.xro/2.0/InvoiceReminders/Settings"
+	invoiceRemindersPath = "InvoiceReminders/Settings"
 )
 
 // InvoiceReminder will keep information about invoicing settings
@@ -21,9 +20,20 @@ type InvoiceReminders struct {
 	InvoiceReminders []InvoiceReminder `json:"InvoiceReminders,omitempty"`
 }
 
-// FindInvoiceReminders will get all the invoice reminders from Xero
-func FindInvoiceReminders(cl *http.Client) (ir *InvoiceReminders, err error) {
-	invoiceRemindersBytes, err := helpers.Find(cl, invoiceRemindersURL, nil, nil)
+// InvoiceReminderService gives access to the InvoiceReminders endpoint
+type InvoiceReminderService struct {
+	s *Service
+}
+
+// InvoiceReminders method will return the service for the InvoiceReminders
+// endpoint
+func (s *Service) InvoiceReminders() *InvoiceReminderService {
+	return &InvoiceReminderService{s: s}
+}
+
+// Get method will get the invoice reminders settings
+func (is *InvoiceReminderService) Get(ctx context.Context) (ir *InvoiceReminders, err error) {
+	invoiceRemindersBytes, err := is.s.find(withOperation(ctx, "InvoiceReminders", "Get"), is.s.endpoint(invoiceRemindersPath), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -32,3 +42,13 @@ func FindInvoiceReminders(cl *http.Client) (ir *InvoiceReminders, err error) {
 	}
 	return ir, nil
 }
+
+// FindInvoiceReminders will get all the invoice reminders from Xero
+func FindInvoiceReminders(cl *http.Client) (ir *InvoiceReminders, err error) {
+	return FindInvoiceRemindersContext(context.Background(), cl)
+}
+
+// FindInvoiceRemindersContext is the same as FindInvoiceReminders but the given context is used for the request
+func FindInvoiceRemindersContext(ctx context.Context, cl *http.Client) (ir *InvoiceReminders, err error) {
+	return defaultService(cl).InvoiceReminders().Get(ctx)
+}
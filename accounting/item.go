@@ -1,15 +1,18 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
 	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/decimal"
 	"github.com/quickaco/xerosdk/helpers"
+	"github.com/quickaco/xerosdk/xerotime"
 )
 
 const (
-	itemURL = "https://api.xero.com/api.xro/2.0/Items"
+	itemPath = "Items"
 )
 
 //Item is something that is sold or purchased.  It can have inventory tracked or not tracked.
@@ -46,16 +49,31 @@ type Item struct {
 	IsTrackedAsInventory bool `json:"IsTrackedAsInventory,omitempty"`
 
 	// The value of the item on hand. Calculated using average cost accounting.
-	TotalCostPool float64 `json:"TotalCostPool,omitempty"`
+	TotalCostPool decimal.Decimal `json:"TotalCostPool,omitempty"`
 
 	// The quantity of the item on hand
-	QuantityOnHand float64 `json:"QuantityOnHand,omitempty"`
+	QuantityOnHand decimal.Decimal `json:"QuantityOnHand,omitempty"`
 
 	// Last modified date in UTC format
-	UpdatedDateUTC string `json:"UpdatedDateUTC,omitempty"`
+	UpdatedDateUTC xerotime.DateTime `json:"UpdatedDateUTC,omitempty"`
 
 	// The Xero identifier for an Item
 	ItemID string `json:"ItemID,omitempty"`
+
+	// Status of the element when it's sent with summarizeErrors=false, OK or
+	// ERROR
+	StatusAttributeString string `json:"StatusAttributeString,omitempty"`
+
+	// Validation errors of the element when it's sent with
+	// summarizeErrors=false
+	ValidationErrors []helpers.ValidationError `json:"ValidationErrors,omitempty"`
+}
+
+// MarshalJSON method will leave out the zero dates and amounts, see
+// marshalOmitZero
+func (i Item) MarshalJSON() ([]byte, error) {
+	type item Item
+	return marshalOmitZero(item(i))
 }
 
 //Items is a collection of Items
@@ -66,7 +84,7 @@ type Items struct {
 //PurchaseAndSaleDetails are Elements for Purchases and Sales
 type PurchaseAndSaleDetails struct {
 	//Unit Price of the item. By default UnitPrice is returned to two decimal places.  You can use 4 decimal places by adding the unitdp=4 querystring parameter to your request.
-	UnitPrice float64 `json:"UnitPrice,omitempty"`
+	UnitPrice decimal.Decimal `json:"UnitPrice,omitempty"`
 
 	//Default account code to be used for purchased/sale. Not applicable to the purchase details of tracked items
 	AccountCode string `json:"AccountCode,omitempty"`
@@ -78,18 +96,10 @@ type PurchaseAndSaleDetails struct {
 	TaxType string `json:"TaxType,omitempty"`
 }
 
-//The Xero API returns Dates based on the .Net JSON date format available at the time of development
-//We need to convert these to a more usable format - RFC3339 for consistency with what the API expects to recieve
-func (i *Items) convertDates() error {
-	var err error
-	for n := len(i.Items) - 1; n >= 0; n-- {
-		i.Items[n].UpdatedDateUTC, err = helpers.DotNetJSONTimeToRFC3339(i.Items[n].UpdatedDateUTC, true)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+// MarshalJSON method will leave out the zero amounts, see marshalOmitZero
+func (d PurchaseAndSaleDetails) MarshalJSON() ([]byte, error) {
+	type purchaseAndSaleDetails PurchaseAndSaleDetails
+	return marshalOmitZero(purchaseAndSaleDetails(d))
 }
 
 func unmarshalItem(itemResponseBytes []byte) (*Items, error) {
@@ -99,21 +109,54 @@ func unmarshalItem(itemResponseBytes []byte) (*Items, error) {
 		return nil, err
 	}
 
-	err = itemResponse.convertDates()
+	return itemResponse, nil
+}
+
+// ItemService gives access to the Items endpoint
+type ItemService struct {
+	s *Service
+}
+
+// Items method will return the service for the Items endpoint
+func (s *Service) Items() *ItemService {
+	return &ItemService{s: s}
+}
+
+// List method will get all the Items, additionalHeaders and
+// querystringParameters can be added as maps
+func (is *ItemService) List(ctx context.Context, additionalHeaders map[string]string, queryParameters map[string]string) (*Items, error) {
+	itemsResponseBytes, err := is.s.find(withOperation(ctx, "Items", "List"), is.s.endpoint(itemPath), additionalHeaders, queryParameters)
 	if err != nil {
 		return nil, err
 	}
 
-	return itemResponse, err
+	return unmarshalItem(itemsResponseBytes)
 }
 
-// Create will create items given an Items struct
-func (i *Items) Create(cl *http.Client) (*Items, error) {
+// Get method will get a single Item
+func (is *ItemService) Get(ctx context.Context, itemID uuid.UUID) (*Item, error) {
+	itemsResponseBytes, err := is.s.find(withOperation(ctx, "Items", "Get"), is.s.endpoint(itemPath, itemID.String()), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := unmarshalItem(itemsResponseBytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(items.Items) > 0 {
+		return &items.Items[0], nil
+	}
+	return nil, nil
+}
+
+// Create method will create the given Items
+func (is *ItemService) Create(ctx context.Context, i *Items) (*Items, error) {
 	buf, err := json.Marshal(i)
 	if err != nil {
 		return nil, err
 	}
-	itemsResponseBytes, err := helpers.Create(cl, itemURL, buf)
+	itemsResponseBytes, err := is.s.create(withOperation(ctx, "Items", "Create"), is.s.endpoint(itemPath), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -121,9 +164,8 @@ func (i *Items) Create(cl *http.Client) (*Items, error) {
 	return unmarshalItem(itemsResponseBytes)
 }
 
-// Update will update an item given an Items struct
-// This will only handle single item - you cannot update multiple items in a single call
-func (i *Item) Update(cl *http.Client) (*Items, error) {
+// Update method will update the given Item
+func (is *ItemService) Update(ctx context.Context, i *Item) (*Items, error) {
 	its := Items{
 		Items: []Item{*i},
 	}
@@ -131,7 +173,7 @@ func (i *Item) Update(cl *http.Client) (*Items, error) {
 	if err != nil {
 		return nil, err
 	}
-	itemsResponseBytes, err := helpers.Update(cl, itemURL+"/"+i.ItemID, buf)
+	itemsResponseBytes, err := is.s.update(withOperation(ctx, "Items", "Update"), is.s.endpoint(itemPath, i.ItemID), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -139,9 +181,9 @@ func (i *Item) Update(cl *http.Client) (*Items, error) {
 	return unmarshalItem(itemsResponseBytes)
 }
 
-// FindItems will get all items.
-func FindItems(cl *http.Client, additionalHeaders map[string]string, queryParameters map[string]string) (*Items, error) {
-	itemsResponseBytes, err := helpers.Find(cl, itemURL, additionalHeaders, queryParameters)
+// Remove method will delete a single Item
+func (is *ItemService) Remove(ctx context.Context, itemID uuid.UUID) (*Items, error) {
+	itemsResponseBytes, err := is.s.remove(withOperation(ctx, "Items", "Remove"), is.s.endpoint(itemPath, itemID.String()))
 	if err != nil {
 		return nil, err
 	}
@@ -149,26 +191,53 @@ func FindItems(cl *http.Client, additionalHeaders map[string]string, queryParame
 	return unmarshalItem(itemsResponseBytes)
 }
 
+// Create will create items given an Items struct
+func (i *Items) Create(cl *http.Client) (*Items, error) {
+	return i.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the given context is used for the request
+func (i *Items) CreateContext(ctx context.Context, cl *http.Client) (*Items, error) {
+	return defaultService(cl).Items().Create(ctx, i)
+}
+
+// Update will update an item given an Items struct
+// This will only handle single item - you cannot update multiple items in a single call
+func (i *Item) Update(cl *http.Client) (*Items, error) {
+	return i.UpdateContext(context.Background(), cl)
+}
+
+// UpdateContext is the same as Update but the given context is used for the request
+func (i *Item) UpdateContext(ctx context.Context, cl *http.Client) (*Items, error) {
+	return defaultService(cl).Items().Update(ctx, i)
+}
+
+// FindItems will get all items.
+func FindItems(cl *http.Client, additionalHeaders map[string]string, queryParameters map[string]string) (*Items, error) {
+	return FindItemsContext(context.Background(), cl, additionalHeaders, queryParameters)
+}
+
+// FindItemsContext is the same as FindItems but the given context is used for the request
+func FindItemsContext(ctx context.Context, cl *http.Client, additionalHeaders map[string]string, queryParameters map[string]string) (*Items, error) {
+	return defaultService(cl).Items().List(ctx, additionalHeaders, queryParameters)
+}
+
 //FindItem will get a single item - itemID must be a GUID for an item
 func FindItem(cl *http.Client, itemID uuid.UUID) (*Item, error) {
-	itemsResponseBytes, err := helpers.Find(cl, itemURL+"/"+itemID.String(), nil, nil)
-	if err != nil {
-		return nil, err
-	}
+	return FindItemContext(context.Background(), cl, itemID)
+}
 
-	items, err := unmarshalItem(itemsResponseBytes)
-	if err != nil {
-		return nil, err
-	}
-	return &items.Items[0], nil
+// FindItemContext is the same as FindItem but the given context is used for the request
+func FindItemContext(ctx context.Context, cl *http.Client, itemID uuid.UUID) (*Item, error) {
+	return defaultService(cl).Items().Get(ctx, itemID)
 }
 
 //RemoveItem will get a single item - itemID must be a GUID for an item
 func RemoveItem(cl *http.Client, itemID uuid.UUID) (*Items, error) {
-	itemsResponseBytes, err := helpers.Remove(cl, itemURL+"/"+itemID.String())
-	if err != nil {
-		return nil, err
-	}
+	return RemoveItemContext(context.Background(), cl, itemID)
+}
 
-	return unmarshalItem(itemsResponseBytes)
+// RemoveItemContext is the same as RemoveItem but the given context is used for the request
+func RemoveItemContext(ctx context.Context, cl *http.Client, itemID uuid.UUID) (*Items, error) {
+	return defaultService(cl).Items().Remove(ctx, itemID)
 }
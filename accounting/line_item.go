@@ -1,5 +1,7 @@
 package accounting
 
+import "github.com/quickaco/xerosdk/decimal"
+
 //LineItem is a line containing detail on an Invoice
 type LineItem struct {
 	//The Xero generated identifier for a LineItem. It is recommended that you include LineItemIDs on update requests. If LineItemIDs are not included with line items in an update request then the line items are deleted and recreated.
@@ -9,10 +11,10 @@ type LineItem struct {
 	Description string `json:"Description,omitempty"`
 
 	// LineItem Quantity
-	Quantity float64 `json:"Quantity,omitempty"`
+	Quantity decimal.Decimal `json:"Quantity,omitempty"`
 
 	// LineItem Unit Amount
-	UnitAmount float64 `json:"UnitAmount,omitempty"`
+	UnitAmount decimal.Decimal `json:"UnitAmount,omitempty"`
 
 	// See Items
 	ItemCode string `json:"ItemCode,omitempty"`
@@ -24,20 +26,26 @@ type LineItem struct {
 	TaxType string `json:"TaxType,omitempty"`
 
 	// The tax amount is auto calculated as a percentage of the line amount (see below) based on the tax rate. This value can be overriden if the calculated <TaxAmount> is not correct.
-	TaxAmount float64 `json:"TaxAmount,omitempty"`
+	TaxAmount decimal.Decimal `json:"TaxAmount,omitempty"`
 
 	// If you wish to omit either of the <Quantity> or <UnitAmount> you can provide a LineAmount and Xero will calculate the missing amount for you. The line amount reflects the discounted price if a DiscountRate has been used . i.e LineAmount = Quantity * Unit Amount * ((100 – DiscountRate)/100)
-	LineAmount float64 `json:"LineAmount,omitempty"`
+	LineAmount decimal.Decimal `json:"LineAmount,omitempty"`
 
 	// Optional Tracking Category – see Tracking.  Any LineItem can have a maximum of 2 <TrackingCategory> elements.
 	Tracking []TrackingCategory `json:"Tracking,omitempty"`
 
 	// Percentage discount being applied to a line item (only supported on ACCREC invoices – ACC PAY invoices and credit notes in Xero do not support discounts
-	DiscountRate float64 `json:"DiscountRate,omitempty"`
+	DiscountRate decimal.Decimal `json:"DiscountRate,omitempty"`
 
 	// The discount amount being applied to a line item (only supported on ACCREC invoices – ACC PAY invoices and credit notes in Xero do not support discounts
-	DiscountAmount float64 `json:"DiscountAmount,omitempty"`
+	DiscountAmount decimal.Decimal `json:"DiscountAmount,omitempty"`
 
 	// The Xero identifier for a Repeating Invoicee.g. 297c2dc5-cc47-4afd-8ec8-74990b8761e9
 	RepeatingInvoiceID string `json:"RepeatingInvoiceID,omitempty"`
 }
+
+// MarshalJSON method will leave out the zero amounts, see marshalOmitZero
+func (l LineItem) MarshalJSON() ([]byte, error) {
+	type lineItem LineItem
+	return marshalOmitZero(lineItem(l))
+}
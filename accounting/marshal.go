@@ -0,0 +1,116 @@
+package accounting
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// zeroer is implemented by the dates and amounts of the models. They're
+// structs, which encoding/json never leaves out with omitempty
+type zeroer interface {
+	IsZero() bool
+}
+
+// modelField is an exported field of a model with the key it's sent with
+type modelField struct {
+	index     int
+	key       []byte
+	omitempty bool
+	zeroer    bool
+}
+
+// modelFields keeps the modelField of each model type
+var modelFields sync.Map
+
+var zeroerType = reflect.TypeOf((*zeroer)(nil)).Elem()
+
+// marshalOmitZero will marshal the given model, which must be given as a type
+// defined on it so its MarshalJSON is not called again, leaving out the zero
+// dates and amounts tagged with omitempty as the zero strings and float64
+// were. The fields are written in a single pass in the order of the struct,
+// as encoding/json does, the models have no embedded structs to flatten
+func marshalOmitZero(v interface{}) ([]byte, error) {
+	value := reflect.ValueOf(v)
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for _, field := range fieldsOf(value.Type()) {
+		fieldValue := value.Field(field.index)
+		if field.omitempty && isEmpty(fieldValue, field.zeroer) {
+			continue
+		}
+		data, err := json.Marshal(fieldValue.Interface())
+		if err != nil {
+			return nil, err
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.Write(field.key)
+		buf.Write(data)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// isEmpty will tell if the value is left out by omitempty, the zeroer values
+// are empty when they're zero
+func isEmpty(v reflect.Value, isZeroer bool) bool {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return true
+	}
+	if isZeroer {
+		return v.Interface().(zeroer).IsZero()
+	}
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+func fieldsOf(t reflect.Type) []modelField {
+	if cached, ok := modelFields.Load(t); ok {
+		return cached.([]modelField)
+	}
+	var fields []modelField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		tag := strings.Split(field.Tag.Get("json"), ",")
+		if tag[0] == "-" && len(tag) == 1 {
+			continue
+		}
+		name := tag[0]
+		if name == "" {
+			name = field.Name
+		}
+		key, _ := json.Marshal(name)
+		omitempty := false
+		for _, option := range tag[1:] {
+			omitempty = omitempty || option == "omitempty"
+		}
+		fields = append(fields, modelField{
+			index:     i,
+			key:       append(key, ':'),
+			omitempty: omitempty,
+			zeroer:    field.Type.Implements(zeroerType),
+		})
+	}
+	modelFields.Store(t, fields)
+	return fields
+}
@@ -0,0 +1,49 @@
+package accounting
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/quickaco/xerosdk/decimal"
+	"github.com/quickaco/xerosdk/xerotime"
+)
+
+func TestMarshalOmitZero(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{
+			name: "zero amounts left out",
+			value: LineItem{
+				Description: "Widget",
+				Quantity:    decimal.NewFromInt(3),
+				UnitAmount:  decimal.MustParse("19.99"),
+				AccountCode: "200",
+			},
+			want: `{"Description":"Widget","Quantity":3,"UnitAmount":19.99,"AccountCode":"200"}`,
+		},
+		{
+			name: "fields without omitempty kept",
+			value: Invoice{
+				Type:     "ACCREC",
+				Date:     xerotime.NewDate(2020, 1, 31),
+				Status:   "DRAFT",
+				Payments: &[]Payment{},
+			},
+			want: `{"Type":"ACCREC","Contact":{"BatchPayments":{},"Balances":{"AccountsReceivable":{},"AccountsPayable":{}}},"LineItems":null,"DateString":"2020-01-31","Status":"DRAFT","Payments":[]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("Marshal = %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
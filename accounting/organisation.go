@@ -1,14 +1,16 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"time"
 
-	"github.com/quickaco/xerosdk/helpers"
+	"github.com/quickaco/xerosdk/xerotime"
 )
 
 const (
-	organisationURL = "https://api.xero.com/api.xro/2.0/Organisations"
+	organisationPath = "Organisations"
 )
 
 //Organisation is information about a Xero organisation
@@ -69,13 +71,13 @@ type Organisation struct {
 	DefaultPurchasesTax string `json:"DefaultPurchasesTax,omitempty"`
 
 	// Shown if set. See lock dates
-	PeriodLockDate string `json:"PeriodLockDate,omitempty"`
+	PeriodLockDate xerotime.Date `json:"PeriodLockDate,omitempty"`
 
 	// Shown if set. See lock dates
-	EndOfYearLockDate string `json:"EndOfYearLockDate,omitempty"`
+	EndOfYearLockDate xerotime.Date `json:"EndOfYearLockDate,omitempty"`
 
 	// Timestamp when the organisation was created in Xero
-	CreatedDateUTC string `json:"CreatedDateUTC,omitempty"`
+	CreatedDateUTC xerotime.DateTime `json:"CreatedDateUTC,omitempty"`
 
 	// Timezone specifications
 	Timezone string `json:"Timezone,omitempty"`
@@ -102,14 +104,38 @@ type Organisation struct {
 	ExternalLinks []ExternalLink `json:"ExternalLinks,omitempty"`
 }
 
+// MarshalJSON method will leave out the zero dates and amounts, see
+// marshalOmitZero
+func (o Organisation) MarshalJSON() ([]byte, error) {
+	type organisation Organisation
+	return marshalOmitZero(organisation(o))
+}
+
+// Location method will return the location of the timezone of the
+// organisation, date fields such as PeriodLockDate are days in it
+func (o *Organisation) Location() (*time.Location, error) {
+	return xerotime.Location(o.Timezone)
+}
+
 //OrganisationCollection contains a collection of Organisations - but there will only ever be one. Like Highlander
 type OrganisationCollection struct {
 	Organisations []Organisation `json:"Organisations,omitempty"`
 }
 
-// FindOrganisations will get all the organisation linked to the given tenantID
-func FindOrganisations(cl *http.Client) (org *OrganisationCollection, err error) {
-	organisationBytes, err := helpers.Find(cl, organisationURL, nil, nil)
+// OrganisationService gives access to the Organisations endpoint
+type OrganisationService struct {
+	s *Service
+}
+
+// Organisations method will return the service for the Organisations endpoint
+func (s *Service) Organisations() *OrganisationService {
+	return &OrganisationService{s: s}
+}
+
+// List method will get all the organisations linked to the tenant of the
+// client
+func (o *OrganisationService) List(ctx context.Context) (org *OrganisationCollection, err error) {
+	organisationBytes, err := o.s.find(withOperation(ctx, "Organisations", "List"), o.s.endpoint(organisationPath), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -117,15 +143,15 @@ func FindOrganisations(cl *http.Client) (org *OrganisationCollection, err error)
 	if err != nil {
 		return nil, err
 	}
-	for index := range org.Organisations {
-		org.Organisations[index].PeriodLockDate, err = helpers.DotNetJSONTimeToRFC3339(org.Organisations[index].PeriodLockDate, false)
-		if err != nil {
-			return nil, err
-		}
-		org.Organisations[index].CreatedDateUTC, err = helpers.DotNetJSONTimeToRFC3339(org.Organisations[index].CreatedDateUTC, true)
-		if err != nil {
-			return nil, err
-		}
-	}
 	return org, nil
 }
+
+// FindOrganisations will get all the organisation linked to the given tenantID
+func FindOrganisations(cl *http.Client) (org *OrganisationCollection, err error) {
+	return FindOrganisationsContext(context.Background(), cl)
+}
+
+// FindOrganisationsContext is the same as FindOrganisations but the given context is used for the request
+func FindOrganisationsContext(ctx context.Context, cl *http.Client) (org *OrganisationCollection, err error) {
+	return defaultService(cl).Organisations().List(ctx)
+}
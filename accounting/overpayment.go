@@ -1,5 +1,10 @@
 package accounting
 
+import (
+	"github.com/quickaco/xerosdk/decimal"
+	"github.com/quickaco/xerosdk/xerotime"
+)
+
 //Overpayment is used when a debtor overpays an invoice
 type Overpayment struct {
 
@@ -7,7 +12,7 @@ type Overpayment struct {
 	Type string `json:"Type,omitempty"`
 
 	// The date the overpayment is created YYYY-MM-DD
-	Date string `json:"DateString,omitempty"`
+	Date xerotime.Date `json:"DateString,omitempty"`
 
 	// See Contacts
 	Contact Contact `json:"Contact"`
@@ -22,16 +27,16 @@ type Overpayment struct {
 	LineItems []LineItem `json:"LineItems,omitempty"`
 
 	// The subtotal of the overpayment excluding taxes
-	SubTotal float64 `json:"SubTotal,omitempty"`
+	SubTotal decimal.Decimal `json:"SubTotal,omitempty"`
 
 	// The total tax on the overpayment
-	TotalTax float64 `json:"TotalTax,omitempty"`
+	TotalTax decimal.Decimal `json:"TotalTax,omitempty"`
 
 	// The total of the overpayment (subtotal + total tax)
-	Total float64 `json:"Total,omitempty"`
+	Total decimal.Decimal `json:"Total,omitempty"`
 
 	// UTC timestamp of last update to the overpayment
-	UpdatedDateUTC string `json:"UpdatedDateUTC,omitempty"`
+	UpdatedDateUTC xerotime.DateTime `json:"UpdatedDateUTC,omitempty"`
 
 	// Currency used for the overpayment
 	CurrencyCode string `json:"CurrencyCode,omitempty"`
@@ -40,10 +45,10 @@ type Overpayment struct {
 	OverpaymentID string `json:"OverpaymentID,omitempty"`
 
 	// The currency rate for a multicurrency overpayment. If no rate is specified, the XE.com day rate is used
-	CurrencyRate float64 `json:"CurrencyRate,omitempty"`
+	CurrencyRate decimal.Decimal `json:"CurrencyRate,omitempty"`
 
 	// The remaining credit balance on the overpayment
-	RemainingCredit float64 `json:"RemainingCredit,omitempty"`
+	RemainingCredit decimal.Decimal `json:"RemainingCredit,omitempty"`
 
 	// See Allocations
 	Allocations []Allocation `json:"Allocations,omitempty"`
@@ -55,6 +60,13 @@ type Overpayment struct {
 	HasAttachments bool `json:"HasAttachments,omitempty"`
 }
 
+// MarshalJSON method will leave out the zero dates and amounts, see
+// marshalOmitZero
+func (o Overpayment) MarshalJSON() ([]byte, error) {
+	type overpayment Overpayment
+	return marshalOmitZero(overpayment(o))
+}
+
 //Overpayments is a collection of Overpayments
 type Overpayments struct {
 	Overpayments []Overpayment `json:"Overpayments"`
@@ -0,0 +1,426 @@
+package accounting
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	pageParameter     = "page"
+	pageSizeParameter = "pageSize"
+
+	// defaultPageSize is the number of elements of a page when pageSize is
+	// not sent
+	defaultPageSize = 100
+)
+
+// PageOptions keeps the criteria used to walk the pages of an endpoint
+type PageOptions struct {
+	// PageSize is the number of elements asked for each page, zero means
+	// the Xero default of 100
+	PageSize int
+
+	// MaxPages is the maximum number of pages fetched, zero means no limit
+	MaxPages int
+
+	// StartPage is the first page fetched, zero means the first one. Use it
+	// with the Page method of an iterator to resume a previous walk from
+	// Page() + 1
+	StartPage int
+
+	// ModifiedSince asks only for the elements modified after the given date
+	// when it's not zero
+	ModifiedSince time.Time
+
+	// QueryParameters are sent on each page request, such as where and order
+	QueryParameters map[string]string
+}
+
+// pager keeps the state shared by all the iterators, it knows which page
+// comes next and when the walk is over
+type pager struct {
+	opts    PageOptions
+	page    int
+	fetched int
+	done    bool
+
+	// first and last are the identifiers of the first and last elements of
+	// the previous page
+	first string
+	last  string
+}
+
+func newPager(opts PageOptions) *pager {
+	page := opts.StartPage
+	if page < 1 {
+		page = 1
+	}
+	return &pager{
+		opts: opts,
+		page: page - 1,
+	}
+}
+
+// request will return the headers and query parameters for the next page,
+// ok is false once the walk is over
+func (p *pager) request() (headers map[string]string, queryParameters map[string]string, ok bool) {
+	if p.done || (p.opts.MaxPages > 0 && p.fetched >= p.opts.MaxPages) {
+		return nil, nil, false
+	}
+	if !p.opts.ModifiedSince.IsZero() {
+		headers = modifiedSinceHeaders(p.opts.ModifiedSince)
+	}
+	queryParameters = make(map[string]string, len(p.opts.QueryParameters)+2)
+	for key, value := range p.opts.QueryParameters {
+		queryParameters[key] = value
+	}
+	queryParameters[pageParameter] = strconv.Itoa(p.page + 1)
+	if p.opts.PageSize > 0 {
+		queryParameters[pageSizeParameter] = strconv.Itoa(p.opts.PageSize)
+	}
+	return headers, queryParameters, true
+}
+
+// advance will move the pager after a page with the given number of elements
+// was fetched, first and last are the identifiers of its first and last
+// elements. False means the page must be dropped as it repeats the previous
+// one. The walk ends on an empty page, on a page shorter than the page size
+// and on a repeated page, which is what an endpoint ignoring the page
+// parameter sends
+func (p *pager) advance(elements int, first string, last string) bool {
+	if elements == 0 {
+		p.done = true
+		return false
+	}
+	if p.fetched > 0 && first != "" && first == p.first && last == p.last {
+		p.done = true
+		return false
+	}
+	p.first, p.last = first, last
+	p.page++
+	p.fetched++
+	if elements < p.pageSize() {
+		p.done = true
+	}
+	return true
+}
+
+// pageSize will return the number of elements of a full page
+func (p *pager) pageSize() int {
+	if p.opts.PageSize > 0 {
+		return p.opts.PageSize
+	}
+	return defaultPageSize
+}
+
+// InvoiceIterator walks the pages of the Invoices endpoint
+type InvoiceIterator struct {
+	is *InvoiceService
+	p  *pager
+}
+
+// Iterator method will return an iterator over the invoices pages
+func (is *InvoiceService) Iterator(opts PageOptions) *InvoiceIterator {
+	return &InvoiceIterator{is: is, p: newPager(opts)}
+}
+
+// Next method will fetch the next page of invoices, an empty slice means
+// there are no more pages
+func (it *InvoiceIterator) Next(ctx context.Context) ([]Invoice, error) {
+	headers, queryParameters, ok := it.p.request()
+	if !ok {
+		return nil, nil
+	}
+	invoices, err := it.is.list(ctx, headers, queryParameters)
+	if err != nil {
+		return nil, err
+	}
+	page := invoices.Invoices
+	first, last := "", ""
+	if len(page) > 0 {
+		first, last = page[0].InvoiceID, page[len(page)-1].InvoiceID
+	}
+	if !it.p.advance(len(page), first, last) {
+		return nil, nil
+	}
+	return invoices.Invoices, nil
+}
+
+// Page method will return the number of the last page returned by Next, the
+// page before StartPage if none was returned yet
+func (it *InvoiceIterator) Page() int {
+	return it.p.page
+}
+
+// All method will fetch all the invoices walking the pages with the given
+// options
+func (is *InvoiceService) All(ctx context.Context, opts PageOptions) ([]Invoice, error) {
+	var all []Invoice
+	it := is.Iterator(opts)
+	for {
+		invoices, err := it.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(invoices) == 0 {
+			return all, nil
+		}
+		all = append(all, invoices...)
+	}
+}
+
+// ContactIterator walks the pages of the Contacts endpoint
+type ContactIterator struct {
+	cs *ContactService
+	p  *pager
+}
+
+// Iterator method will return an iterator over the contacts pages
+func (cs *ContactService) Iterator(opts PageOptions) *ContactIterator {
+	return &ContactIterator{cs: cs, p: newPager(opts)}
+}
+
+// Next method will fetch the next page of contacts, an empty slice means
+// there are no more pages
+func (it *ContactIterator) Next(ctx context.Context) ([]Contact, error) {
+	headers, queryParameters, ok := it.p.request()
+	if !ok {
+		return nil, nil
+	}
+	contacts, err := it.cs.list(ctx, headers, queryParameters)
+	if err != nil {
+		return nil, err
+	}
+	page := contacts.Contacts
+	first, last := "", ""
+	if len(page) > 0 {
+		first, last = page[0].ContactID, page[len(page)-1].ContactID
+	}
+	if !it.p.advance(len(page), first, last) {
+		return nil, nil
+	}
+	return contacts.Contacts, nil
+}
+
+// Page method will return the number of the last page returned by Next, the
+// page before StartPage if none was returned yet
+func (it *ContactIterator) Page() int {
+	return it.p.page
+}
+
+// All method will fetch all the contacts walking the pages with the given
+// options
+func (cs *ContactService) All(ctx context.Context, opts PageOptions) ([]Contact, error) {
+	var all []Contact
+	it := cs.Iterator(opts)
+	for {
+		contacts, err := it.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(contacts) == 0 {
+			return all, nil
+		}
+		all = append(all, contacts...)
+	}
+}
+
+// BankTransferIterator walks the pages of the BankTransfers endpoint
+type BankTransferIterator struct {
+	bs *BankTransferService
+	p  *pager
+}
+
+// Iterator method will return an iterator over the bank transfers pages
+func (bs *BankTransferService) Iterator(opts PageOptions) *BankTransferIterator {
+	return &BankTransferIterator{bs: bs, p: newPager(opts)}
+}
+
+// Next method will fetch the next page of bank transfers, an empty slice
+// means there are no more pages
+func (it *BankTransferIterator) Next(ctx context.Context) ([]BankTransfer, error) {
+	headers, queryParameters, ok := it.p.request()
+	if !ok {
+		return nil, nil
+	}
+	bankTransfers, err := it.bs.list(ctx, headers, queryParameters)
+	if err != nil {
+		return nil, err
+	}
+	page := bankTransfers.BankTransfers
+	first, last := "", ""
+	if len(page) > 0 {
+		first, last = page[0].BankTransferID, page[len(page)-1].BankTransferID
+	}
+	if !it.p.advance(len(page), first, last) {
+		return nil, nil
+	}
+	return bankTransfers.BankTransfers, nil
+}
+
+// Page method will return the number of the last page returned by Next, the
+// page before StartPage if none was returned yet
+func (it *BankTransferIterator) Page() int {
+	return it.p.page
+}
+
+// All method will fetch all the bank transfers walking the pages with the
+// given options
+func (bs *BankTransferService) All(ctx context.Context, opts PageOptions) ([]BankTransfer, error) {
+	var all []BankTransfer
+	it := bs.Iterator(opts)
+	for {
+		bankTransfers, err := it.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(bankTransfers) == 0 {
+			return all, nil
+		}
+		all = append(all, bankTransfers...)
+	}
+}
+
+// CreditNoteIterator walks the pages of the CreditNotes endpoint
+type CreditNoteIterator struct {
+	cs *CreditNoteService
+	p  *pager
+}
+
+// Iterator method will return an iterator over the credit notes pages
+func (cs *CreditNoteService) Iterator(opts PageOptions) *CreditNoteIterator {
+	return &CreditNoteIterator{cs: cs, p: newPager(opts)}
+}
+
+// Next method will fetch the next page of credit notes, an empty slice means
+// there are no more pages
+func (it *CreditNoteIterator) Next(ctx context.Context) ([]CreditNote, error) {
+	headers, queryParameters, ok := it.p.request()
+	if !ok {
+		return nil, nil
+	}
+	creditNotes, err := it.cs.list(ctx, headers, queryParameters)
+	if err != nil {
+		return nil, err
+	}
+	page := creditNotes.CreditNotes
+	first, last := "", ""
+	if len(page) > 0 {
+		first, last = page[0].CreditNoteID, page[len(page)-1].CreditNoteID
+	}
+	if !it.p.advance(len(page), first, last) {
+		return nil, nil
+	}
+	return creditNotes.CreditNotes, nil
+}
+
+// Page method will return the number of the last page returned by Next, the
+// page before StartPage if none was returned yet
+func (it *CreditNoteIterator) Page() int {
+	return it.p.page
+}
+
+// All method will fetch all the credit notes walking the pages with the given
+// options
+func (cs *CreditNoteService) All(ctx context.Context, opts PageOptions) ([]CreditNote, error) {
+	var all []CreditNote
+	it := cs.Iterator(opts)
+	for {
+		creditNotes, err := it.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(creditNotes) == 0 {
+			return all, nil
+		}
+		all = append(all, creditNotes...)
+	}
+}
+
+// BankTransactionIterator walks the pages of the BankTransactions endpoint
+type BankTransactionIterator struct {
+	bs *BankTransactionService
+	p  *pager
+}
+
+// Iterator method will return an iterator over the bank transactions pages
+func (bs *BankTransactionService) Iterator(opts PageOptions) *BankTransactionIterator {
+	return &BankTransactionIterator{bs: bs, p: newPager(opts)}
+}
+
+// Next method will fetch the next page of bank transactions, an empty slice
+// means there are no more pages
+func (it *BankTransactionIterator) Next(ctx context.Context) ([]BankTransaction, error) {
+	headers, queryParameters, ok := it.p.request()
+	if !ok {
+		return nil, nil
+	}
+	bankTransactions, err := it.bs.list(ctx, headers, queryParameters)
+	if err != nil {
+		return nil, err
+	}
+	page := bankTransactions.BankTransactions
+	first, last := "", ""
+	if len(page) > 0 {
+		first, last = page[0].BankTransactionID, page[len(page)-1].BankTransactionID
+	}
+	if !it.p.advance(len(page), first, last) {
+		return nil, nil
+	}
+	return bankTransactions.BankTransactions, nil
+}
+
+// Page method will return the number of the last page returned by Next, the
+// page before StartPage if none was returned yet
+func (it *BankTransactionIterator) Page() int {
+	return it.p.page
+}
+
+// All method will fetch all the bank transactions walking the pages with the
+// given options
+func (bs *BankTransactionService) All(ctx context.Context, opts PageOptions) ([]BankTransaction, error) {
+	var all []BankTransaction
+	it := bs.Iterator(opts)
+	for {
+		bankTransactions, err := it.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(bankTransactions) == 0 {
+			return all, nil
+		}
+		all = append(all, bankTransactions...)
+	}
+}
+
+// FindAllInvoices will get all the invoices walking the pages with the given
+// options
+func FindAllInvoices(ctx context.Context, cl *http.Client, opts PageOptions) ([]Invoice, error) {
+	return defaultService(cl).Invoices().All(ctx, opts)
+}
+
+// FindAllContacts will get all the contacts walking the pages with the given
+// options
+func FindAllContacts(ctx context.Context, cl *http.Client, opts PageOptions) ([]Contact, error) {
+	return defaultService(cl).Contacts().All(ctx, opts)
+}
+
+// FindAllBankTransfers will get all the bank transfers walking the pages with
+// the given options
+func FindAllBankTransfers(ctx context.Context, cl *http.Client, opts PageOptions) ([]BankTransfer, error) {
+	return defaultService(cl).BankTransfers().All(ctx, opts)
+}
+
+// FindAllCreditNotes will get all the credit notes walking the pages with the
+// given options
+func FindAllCreditNotes(ctx context.Context, cl *http.Client, opts PageOptions) ([]CreditNote, error) {
+	return defaultService(cl).CreditNotes().All(ctx, opts)
+}
+
+// FindAllBankTransactions will get all the bank transactions walking the pages
+// with the given options
+func FindAllBankTransactions(ctx context.Context, cl *http.Client, opts PageOptions) ([]BankTransaction, error) {
+	return defaultService(cl).BankTransactions().All(ctx, opts)
+}
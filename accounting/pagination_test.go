@@ -0,0 +1,126 @@
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// contactPages serves the contacts split in pages of pageSize, or the first
+// page whatever the page asked when ignorePage is true. It keeps the pages
+// asked and their If-Modified-Since header
+type contactPages struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	asked         []int
+	modifiedSince []string
+	total      int
+	pageSize   int
+	ignorePage bool
+}
+
+func newContactPages(total int, pageSize int) *contactPages {
+	s := &contactPages{total: total, pageSize: pageSize}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		s.mu.Lock()
+		s.asked = append(s.asked, page)
+		s.modifiedSince = append(s.modifiedSince, r.Header.Get("If-Modified-Since"))
+		s.mu.Unlock()
+		if s.ignorePage {
+			page = 1
+		}
+		var response Contacts
+		for i := (page - 1) * s.pageSize; i < page*s.pageSize && i < s.total; i++ {
+			response.Contacts = append(response.Contacts, Contact{ContactID: strconv.Itoa(i)})
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	return s
+}
+
+func TestContactIterator(t *testing.T) {
+	tests := []struct {
+		name       string
+		total      int
+		serverSize int
+		ignorePage bool
+		opts       PageOptions
+		contacts   int
+		asked      []int
+		page       int
+	}{
+		{name: "empty", total: 0, serverSize: 10, opts: PageOptions{PageSize: 10}, asked: []int{1}},
+		{name: "short last page", total: 25, serverSize: 10, opts: PageOptions{PageSize: 10}, contacts: 25, asked: []int{1, 2, 3}, page: 3},
+		{name: "full last page", total: 20, serverSize: 10, opts: PageOptions{PageSize: 10}, contacts: 20, asked: []int{1, 2, 3}, page: 2},
+		{name: "default page size", total: 150, serverSize: 100, contacts: 150, asked: []int{1, 2}, page: 2},
+		{name: "short page of the default size", total: 50, serverSize: 100, contacts: 50, asked: []int{1}, page: 1},
+		{name: "repeated page", total: 25, serverSize: 10, ignorePage: true, opts: PageOptions{PageSize: 10}, contacts: 10, asked: []int{1, 2}, page: 1},
+		{name: "max pages", total: 50, serverSize: 10, opts: PageOptions{PageSize: 10, MaxPages: 2}, contacts: 20, asked: []int{1, 2}, page: 2},
+		{name: "start page", total: 25, serverSize: 10, opts: PageOptions{PageSize: 10, StartPage: 2}, contacts: 15, asked: []int{2, 3}, page: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newContactPages(tt.total, tt.serverSize)
+			defer server.Close()
+			server.ignorePage = tt.ignorePage
+			it := NewService(server.Client(), server.URL).Contacts().Iterator(tt.opts)
+
+			var contacts []Contact
+			for {
+				page, err := it.Next(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(page) == 0 {
+					break
+				}
+				contacts = append(contacts, page...)
+			}
+			if len(contacts) != tt.contacts {
+				t.Errorf("contacts = %d, want %d", len(contacts), tt.contacts)
+			}
+			if len(server.asked) != len(tt.asked) {
+				t.Fatalf("pages asked = %v, want %v", server.asked, tt.asked)
+			}
+			for i := range tt.asked {
+				if server.asked[i] != tt.asked[i] {
+					t.Errorf("pages asked = %v, want %v", server.asked, tt.asked)
+				}
+			}
+			if it.Page() != tt.page {
+				t.Errorf("Page = %d, want %d", it.Page(), tt.page)
+			}
+		})
+	}
+}
+
+func TestContactIteratorResume(t *testing.T) {
+	server := newContactPages(25, 10)
+	defer server.Close()
+	service := NewService(server.Client(), server.URL)
+
+	it := service.Contacts().Iterator(PageOptions{PageSize: 10, MaxPages: 1})
+	first, err := it.Next(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rest, err := service.Contacts().All(context.Background(), PageOptions{PageSize: 10, StartPage: it.Page() + 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := append(first, rest...)
+	if len(all) != 25 {
+		t.Fatalf("contacts = %d, want 25", len(all))
+	}
+	for i, contact := range all {
+		if contact.ContactID != strconv.Itoa(i) {
+			t.Errorf("contact %d = %s", i, contact.ContactID)
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package accounting
 
+import (
+	"github.com/quickaco/xerosdk/decimal"
+	"github.com/quickaco/xerosdk/xerotime"
+)
+
 //Payment details payments against invoices and CreditNotes
 type Payment struct {
 
@@ -13,13 +18,13 @@ type Payment struct {
 	Account *Account `json:"Account,omitempty"`
 
 	// Date the payment is being made (YYYY-MM-DD) e.g. 2009-09-06
-	Date string `json:"Date,omitempty"`
+	Date xerotime.Date `json:"Date,omitempty"`
 
 	// Exchange rate when payment is received. Only used for non base currency invoices and credit notes e.g. 0.7500
-	CurrencyRate float64 `json:"CurrencyRate,omitempty"`
+	CurrencyRate decimal.Decimal `json:"CurrencyRate,omitempty"`
 
 	// The amount of the payment. Must be less than or equal to the outstanding amount owing on the invoice e.g. 200.00
-	Amount float64 `json:"Amount,omitempty"`
+	Amount decimal.Decimal `json:"Amount,omitempty"`
 
 	// An optional description for the payment e.g. Direct Debit
 	Reference string `json:"Reference,omitempty"`
@@ -34,12 +39,19 @@ type Payment struct {
 	PaymentType string `json:"PaymentType,omitempty"`
 
 	// UTC timestamp of last update to the payment
-	UpdatedDateUTC string `json:"UpdatedDateUTC,omitempty"`
+	UpdatedDateUTC xerotime.DateTime `json:"UpdatedDateUTC,omitempty"`
 
 	// The Xero identifier for an Payment e.g. 297c2dc5-cc47-4afd-8ec8-74990b8761e9
 	PaymentID string `json:"PaymentID,omitempty"`
 }
 
+// MarshalJSON method will leave out the zero dates and amounts, see
+// marshalOmitZero
+func (p Payment) MarshalJSON() ([]byte, error) {
+	type payment Payment
+	return marshalOmitZero(payment(p))
+}
+
 //Payments is a collection of Payments
 type Payments struct {
 	Payments []Payment `json:"Payments"`
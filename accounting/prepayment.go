@@ -1,5 +1,10 @@
 package accounting
 
+import (
+	"github.com/quickaco/xerosdk/decimal"
+	"github.com/quickaco/xerosdk/xerotime"
+)
+
 //Prepayment are payments made before the associated document has been created
 type Prepayment struct {
 
@@ -7,7 +12,7 @@ type Prepayment struct {
 	Type string `json:"Type,omitempty"`
 
 	// The date the prepayment is created YYYY-MM-DD
-	Date string `json:"DateString,omitempty"`
+	Date xerotime.Date `json:"DateString,omitempty"`
 
 	// See Contacts
 	Contact Contact `json:"Contact"`
@@ -22,16 +27,16 @@ type Prepayment struct {
 	LineItems []LineItem `json:"LineItems,omitempty"`
 
 	// The subtotal of the prepayment excluding taxes
-	SubTotal float64 `json:"SubTotal,omitempty"`
+	SubTotal decimal.Decimal `json:"SubTotal,omitempty"`
 
 	// The total tax on the prepayment
-	TotalTax float64 `json:"TotalTax,omitempty"`
+	TotalTax decimal.Decimal `json:"TotalTax,omitempty"`
 
 	// The total of the prepayment(subtotal + total tax)
-	Total float64 `json:"Total,omitempty"`
+	Total decimal.Decimal `json:"Total,omitempty"`
 
 	// UTC timestamp of last update to the prepayment
-	UpdatedDateUTC string `json:"UpdatedDateUTC,omitempty"`
+	UpdatedDateUTC xerotime.DateTime `json:"UpdatedDateUTC,omitempty"`
 
 	// Currency used for the prepayment
 	CurrencyCode string `json:"CurrencyCode,omitempty"`
@@ -40,10 +45,10 @@ type Prepayment struct {
 	PrepaymentID string `json:"PrepaymentID,omitempty"`
 
 	// The currency rate for a multicurrency prepayment. If no rate is specified, the XE.com day rate is used
-	CurrencyRate float64 `json:"CurrencyRate,omitempty"`
+	CurrencyRate decimal.Decimal `json:"CurrencyRate,omitempty"`
 
 	// The remaining credit balance on the prepayment
-	RemainingCredit float64 `json:"RemainingCredit,omitempty"`
+	RemainingCredit decimal.Decimal `json:"RemainingCredit,omitempty"`
 
 	// See Allocations
 	Allocations []Allocation `json:"Allocations,omitempty"`
@@ -52,6 +57,13 @@ type Prepayment struct {
 	HasAttachments bool `json:"HasAttachments,omitempty"`
 }
 
+// MarshalJSON method will leave out the zero dates and amounts, see
+// marshalOmitZero
+func (p Prepayment) MarshalJSON() ([]byte, error) {
+	type prepayment Prepayment
+	return marshalOmitZero(prepayment(p))
+}
+
 //Prepayments is a collection of Prepayments
 type Prepayments struct {
 	Prepayments []Prepayment `json:"Prepayments"`
@@ -0,0 +1,92 @@
+package accounting
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/quickaco/xerosdk/helpers"
+)
+
+const (
+	// DefaultBaseURL is the base URL of the Xero accounting API
+	DefaultBaseURL = "https://api.xero.com/api.xro/2.0/"
+
+	summarizeErrorsQuery = "summarizeErrors=false"
+)
+
+// Service keeps the http.Client and the base URL used to reach the Xero
+// accounting API, every resource of the API is reachable from it
+type Service struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewService function will build a new Service with the given http.Client,
+// if baseURL is empty DefaultBaseURL is used
+func NewService(cl *http.Client, baseURL string) *Service {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return &Service{
+		client:  cl,
+		baseURL: baseURL,
+	}
+}
+
+// defaultService is used by the package level functions, which always reach
+// the production API
+func defaultService(cl *http.Client) *Service {
+	return NewService(cl, DefaultBaseURL)
+}
+
+// endpoint will build the URL for the given path segments
+func (s *Service) endpoint(segments ...string) string {
+	return s.baseURL + strings.Join(segments, "/")
+}
+
+func (s *Service) find(ctx context.Context, endpoint string, additionalHeaders map[string]string, queryParameters map[string]string) ([]byte, error) {
+	return helpers.FindContext(ctx, s.client, endpoint, additionalHeaders, queryParameters)
+}
+
+func (s *Service) create(ctx context.Context, endpoint string, body []byte) ([]byte, error) {
+	return helpers.CreateContext(ctx, s.client, endpoint, body)
+}
+
+// createBatch will create the elements of the given body asking Xero for a
+// result per element instead of failing the whole request
+func (s *Service) createBatch(ctx context.Context, endpoint string, body []byte) ([]byte, error) {
+	return helpers.CreateContext(ctx, s.client, endpoint+"?"+summarizeErrorsQuery, body)
+}
+
+// updateBatch will create or update the elements of the given body asking
+// Xero for a result per element instead of failing the whole request
+func (s *Service) updateBatch(ctx context.Context, endpoint string, body []byte) ([]byte, error) {
+	return helpers.UpdateContext(ctx, s.client, endpoint+"?"+summarizeErrorsQuery, body)
+}
+
+func (s *Service) update(ctx context.Context, endpoint string, body []byte) ([]byte, error) {
+	return helpers.UpdateContext(ctx, s.client, endpoint, body)
+}
+
+func (s *Service) remove(ctx context.Context, endpoint string) ([]byte, error) {
+	return helpers.RemoveContext(ctx, s.client, endpoint)
+}
+
+// withOperation will tell the interceptors which operation the requests made
+// with the returned context belong to
+func withOperation(ctx context.Context, resource string, name string) context.Context {
+	return helpers.WithOperation(ctx, helpers.Operation{Resource: resource, Name: name})
+}
+
+// modifiedSinceHeaders will build the headers used to ask only for the
+// elements modified after the given date
+func modifiedSinceHeaders(modifiedSince time.Time) map[string]string {
+	return map[string]string{
+		"If-Modified-Since": modifiedSince.Format(time.RFC3339),
+	}
+}
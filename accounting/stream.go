@@ -0,0 +1,188 @@
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/quickaco/xerosdk/helpers"
+)
+
+// errRepeatedPage stops the decoding of a page that starts as the previous
+// one, as sent by an endpoint that ignores the page parameter
+var errRepeatedPage = errors.New("accounting: repeated page")
+
+// pageStream follows the elements of the page being streamed
+type pageStream struct {
+	p        *pager
+	elements int
+	first    string
+	last     string
+}
+
+// next will record the element with the given identifier, false means the
+// page repeats the previous one and the element must not be given to the
+// caller
+func (ps *pageStream) next(id string) bool {
+	if ps.elements == 0 {
+		if ps.p.fetched > 0 && id != "" && id == ps.p.first {
+			return false
+		}
+		ps.first = id
+	}
+	ps.elements++
+	ps.last = id
+	return true
+}
+
+// stream will walk the pages of the given endpoint with the given options,
+// decoding the array kept under key in each response one element at a time
+func (s *Service) stream(ctx context.Context, endpoint string, opts PageOptions, key string, element func(dec *json.Decoder, ps *pageStream) error) error {
+	p := newPager(opts)
+	for {
+		headers, queryParameters, ok := p.request()
+		if !ok {
+			return nil
+		}
+		ps := &pageStream{p: p}
+		err := helpers.StreamContext(ctx, s.client, endpoint, headers, queryParameters, func(body io.Reader) error {
+			return decodeArray(body, key, func(dec *json.Decoder) error {
+				return element(dec, ps)
+			})
+		})
+		if err == errRepeatedPage {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !p.advance(ps.elements, ps.first, ps.last) {
+			return nil
+		}
+	}
+}
+
+// decodeArray will walk the JSON object read from r calling element for each
+// element of the array kept under key, the rest of the fields are skipped
+func decodeArray(r io.Reader, key string, element func(dec *json.Decoder) error) error {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if name, _ := token.(string); name != key {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return err
+			}
+			continue
+		}
+
+		token, err = dec.Token()
+		if err != nil {
+			return err
+		}
+		if token == nil {
+			continue
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return fmt.Errorf("accounting: expected an array for %s, got %v", key, token)
+		}
+		for dec.More() {
+			if err := element(dec); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+func expectDelim(dec *json.Decoder, expected json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != expected {
+		return fmt.Errorf("accounting: expected %v, got %v", expected, token)
+	}
+	return nil
+}
+
+// Stream method will call fn for each invoice of the pages walked with the
+// given options. Each page is decoded one invoice at a time, so the memory
+// used doesn't grow with the size of the response. When fn returns an error
+// the stream stops and the error is returned
+func (is *InvoiceService) Stream(ctx context.Context, opts PageOptions, fn func(i *Invoice) error) error {
+	return is.s.stream(withOperation(ctx, "Invoices", "Stream"), is.s.endpoint(invoicePath), opts, "Invoices", func(dec *json.Decoder, ps *pageStream) error {
+		var i Invoice
+		if err := dec.Decode(&i); err != nil {
+			return err
+		}
+		if !ps.next(i.InvoiceID) {
+			return errRepeatedPage
+		}
+		return fn(&i)
+	})
+}
+
+// Stream method will call fn for each contact of the pages walked with the
+// given options. Each page is decoded one contact at a time, so the memory
+// used doesn't grow with the size of the response. When fn returns an error
+// the stream stops and the error is returned
+func (cs *ContactService) Stream(ctx context.Context, opts PageOptions, fn func(c *Contact) error) error {
+	return cs.s.stream(withOperation(ctx, "Contacts", "Stream"), cs.s.endpoint(contactsPath), opts, "Contacts", func(dec *json.Decoder, ps *pageStream) error {
+		var c Contact
+		if err := dec.Decode(&c); err != nil {
+			return err
+		}
+		if !ps.next(c.ContactID) {
+			return errRepeatedPage
+		}
+		return fn(&c)
+	})
+}
+
+// Stream method will call fn for each bank transaction of the pages walked
+// with the given options. Each page is decoded one bank transaction at a
+// time, so the memory used doesn't grow with the size of the response. When
+// fn returns an error the stream stops and the error is returned
+func (bs *BankTransactionService) Stream(ctx context.Context, opts PageOptions, fn func(b *BankTransaction) error) error {
+	return bs.s.stream(withOperation(ctx, "BankTransactions", "Stream"), bs.s.endpoint(bankTransactionPath), opts, "BankTransactions", func(dec *json.Decoder, ps *pageStream) error {
+		var b BankTransaction
+		if err := dec.Decode(&b); err != nil {
+			return err
+		}
+		if !ps.next(b.BankTransactionID) {
+			return errRepeatedPage
+		}
+		return fn(&b)
+	})
+}
+
+// StreamInvoices will call fn for each invoice of the pages walked with the
+// given options, see InvoiceService.Stream
+func StreamInvoices(ctx context.Context, cl *http.Client, opts PageOptions, fn func(i *Invoice) error) error {
+	return defaultService(cl).Invoices().Stream(ctx, opts, fn)
+}
+
+// StreamContacts will call fn for each contact of the pages walked with the
+// given options, see ContactService.Stream
+func StreamContacts(ctx context.Context, cl *http.Client, opts PageOptions, fn func(c *Contact) error) error {
+	return defaultService(cl).Contacts().Stream(ctx, opts, fn)
+}
+
+// StreamBankTransactions will call fn for each bank transaction of the pages
+// walked with the given options, see BankTransactionService.Stream
+func StreamBankTransactions(ctx context.Context, cl *http.Client, opts PageOptions, fn func(b *BankTransaction) error) error {
+	return defaultService(cl).BankTransactions().Stream(ctx, opts, fn)
+}
@@ -0,0 +1,113 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestContactStream(t *testing.T) {
+	server := newContactPages(4500, 2000)
+	defer server.Close()
+	service := NewService(server.Client(), server.URL)
+	since := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var ids []string
+	err := service.Contacts().Stream(context.Background(), PageOptions{PageSize: 2000, ModifiedSince: since}, func(c *Contact) error {
+		ids = append(ids, c.ContactID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 4500 {
+		t.Fatalf("contacts = %d, want 4500", len(ids))
+	}
+	for i, id := range ids {
+		if id != strconv.Itoa(i) {
+			t.Fatalf("contact %d = %s", i, id)
+		}
+	}
+	if len(server.asked) != 3 {
+		t.Errorf("pages asked = %v, want 3", server.asked)
+	}
+	for _, header := range server.modifiedSince {
+		if header != since.Format(time.RFC3339) {
+			t.Errorf("If-Modified-Since = %q, want %s", header, since.Format(time.RFC3339))
+		}
+	}
+}
+
+func TestContactStreamPages(t *testing.T) {
+	tests := []struct {
+		name       string
+		ignorePage bool
+		opts       PageOptions
+		contacts   int
+		asked      int
+	}{
+		{name: "repeated page", ignorePage: true, opts: PageOptions{PageSize: 10}, contacts: 10, asked: 2},
+		{name: "max pages", opts: PageOptions{PageSize: 10, MaxPages: 2}, contacts: 20, asked: 2},
+		{name: "start page", opts: PageOptions{PageSize: 10, StartPage: 3}, contacts: 5, asked: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newContactPages(25, 10)
+			defer server.Close()
+			server.ignorePage = tt.ignorePage
+			contacts := 0
+			err := NewService(server.Client(), server.URL).Contacts().Stream(context.Background(), tt.opts, func(c *Contact) error {
+				contacts++
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if contacts != tt.contacts || len(server.asked) != tt.asked {
+				t.Errorf("contacts = %d in %d pages, want %d in %d", contacts, len(server.asked), tt.contacts, tt.asked)
+			}
+		})
+	}
+}
+
+func TestContactStreamTruncated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Contacts":[{"ContactID":"1","Name":"One"},{"ContactID":"2","Na`))
+	}))
+	defer server.Close()
+
+	var names []string
+	err := NewService(server.Client(), server.URL).Contacts().Stream(context.Background(), PageOptions{}, func(c *Contact) error {
+		names = append(names, c.Name)
+		return nil
+	})
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("err = %v, want io.ErrUnexpectedEOF", err)
+	}
+	if len(names) != 1 || names[0] != "One" {
+		t.Errorf("contacts = %v, want only the complete one", names)
+	}
+}
+
+func TestContactStreamStop(t *testing.T) {
+	server := newContactPages(25, 10)
+	defer server.Close()
+	stop := errors.New("stop")
+
+	contacts := 0
+	err := NewService(server.Client(), server.URL).Contacts().Stream(context.Background(), PageOptions{PageSize: 10}, func(c *Contact) error {
+		contacts++
+		if contacts == 12 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || contacts != 12 || len(server.asked) != 2 {
+		t.Errorf("err = %v after %d contacts and %d pages, want stop after 12 and 2", err, contacts, len(server.asked))
+	}
+}
@@ -3,14 +3,19 @@ package auth
 import (
 	"context"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/helpers"
 	"golang.org/x/oauth2"
 )
 
 const (
-	authURL  = "https://login.xero.com/identity/connect/authorize"
-	tokenURL = "https://identity.xero.com/connect/token"
+	// DefaultAuthURL is the URL of the Xero authorization endpoint
+	DefaultAuthURL = "https://login.xero.com/identity/connect/authorize"
+	// DefaultTokenURL is the URL of the Xero token endpoint
+	DefaultTokenURL = "https://identity.xero.com/connect/token"
 
 	tenantIDHeader = "xero-tenant-id"
 )
@@ -18,33 +23,177 @@ const (
 // Config keeps the information needed for do an OAuth2 connection
 // with Xero
 type Config struct {
-	ClientID     string
+	ClientID string
+
+	// ClientSecret is left empty for the public clients, which authorize
+	// users with PKCE, see Provider.GetAuthURLWithPKCE
 	ClientSecret string
-	Scopes       []string
-	RedirectURL  string
+
+	Scopes      []string
+	RedirectURL string
+
+	// AuthURL and TokenURL override the Xero identity endpoints, when empty
+	// DefaultAuthURL and DefaultTokenURL are used
+	AuthURL  string
+	TokenURL string
+
+	// RevocationURL overrides the Xero token revocation endpoint, when empty
+	// DefaultRevocationURL is used
+	RevocationURL string
+
+	// Issuer and JWKSURL override the issuer expected in the ID tokens and
+	// the URL of the keys they are signed with, when empty DefaultIssuer and
+	// DefaultJWKSURL are used
+	Issuer  string
+	JWKSURL string
+
+	// JWKSCacheTTL is how long the keys of JWKSURL are kept, when zero
+	// DefaultJWKSCacheTTL is used
+	JWKSCacheTTL time.Duration
+
+	// ConnectionsURL overrides the Xero connections endpoint used to find the
+	// tenant of a custom connection, when empty connection.DefaultURL is used
+	ConnectionsURL string
+
+	// Transport is the base transport used for the API calls, if it's nil
+	// http.DefaultTransport is used
+	Transport http.RoundTripper
+
+	// RetryPolicy enables retrying the API calls rejected by rate limits or
+	// failed with a network error, see helpers.DefaultRetryPolicy
+	RetryPolicy *helpers.RetryPolicy
+
+	// Interceptors are called for each API call, in the given order, see
+	// helpers.LoggingInterceptor
+	Interceptors []helpers.Interceptor
+
+	// Observer is told about each API call, retry and token refresh, see
+	// helpers.Metrics
+	Observer helpers.Observer
+
+	// RateLimitTracker, when set, keeps the rate limits reported by Xero on
+	// each response for each tenant
+	RateLimitTracker *helpers.RateLimitTracker
+
+	// Throttle, when set, holds back the API calls that would exceed the given
+	// limits, see helpers.DefaultThrottleLimits
+	Throttle *helpers.ThrottleLimits
+
+	// Cache, when set, serves the reference data from the given cache instead
+	// of asking Xero each time, see helpers.NewCache
+	Cache *helpers.Cache
+
+	// DryRun, when set, captures the writes in the given plan instead of
+	// sending them, the reads are still sent
+	DryRun *helpers.Plan
 }
 
 // Provider type will keep the minimum structure for make the connection
-// between quicka and Xero
+// between quicka and Xero. The transport shared by all the clients is built
+// once, when the Provider is created, base is the one it's built on
 type Provider struct {
-	conf *oauth2.Config
-	ctx  context.Context
+	conf           *oauth2.Config
+	ctx            context.Context
+	base           http.RoundTripper
+	transport      http.RoundTripper
+	observer       helpers.Observer
+	connectionsURL string
+	revocationURL  string
+	dryRun         *helpers.Plan
+	issuer         string
+	jwks           *jwks
+
+	clientCredentialsOnce sync.Once
+	clientCredentials     *ClientCredentialsSource
+
+	refreshLocks userLocks
 }
 
 // NewProvider function will build a new Provider with the given criteria
 func NewProvider(c Config) *Provider {
+	base := c.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	transport := base
+	if c.RateLimitTracker != nil {
+		transport = helpers.NewRateLimitTransport(transport, c.RateLimitTracker)
+	}
+	if c.Throttle != nil {
+		transport = helpers.NewThrottleTransport(transport, *c.Throttle)
+	}
+	if c.RetryPolicy != nil {
+		retry := helpers.NewRetryTransport(transport, *c.RetryPolicy)
+		retry.Observer = c.Observer
+		transport = retry
+	}
+	if c.Observer != nil {
+		transport = helpers.NewObserverTransport(transport, c.Observer)
+	}
+	if c.Cache != nil {
+		transport = helpers.NewCacheTransport(transport, c.Cache)
+	}
+	if c.DryRun != nil {
+		transport = helpers.NewDryRunTransport(transport, c.DryRun)
+	}
+	if len(c.Interceptors) > 0 {
+		transport = helpers.NewInterceptorTransport(transport, c.Interceptors...)
+	}
+	authURL := c.AuthURL
+	if authURL == "" {
+		authURL = DefaultAuthURL
+	}
+	tokenURL := c.TokenURL
+	if tokenURL == "" {
+		tokenURL = DefaultTokenURL
+	}
+	revocationURL := c.RevocationURL
+	if revocationURL == "" {
+		revocationURL = DefaultRevocationURL
+	}
+	issuer := c.Issuer
+	if issuer == "" {
+		issuer = DefaultIssuer
+	}
+	jwksURL := c.JWKSURL
+	if jwksURL == "" {
+		jwksURL = DefaultJWKSURL
+	}
+	jwksTTL := c.JWKSCacheTTL
+	if jwksTTL <= 0 {
+		jwksTTL = DefaultJWKSCacheTTL
+	}
+	// Public clients, which use PKCE, have no secret and must send their
+	// client_id in the body of the token requests
+	authStyle := oauth2.AuthStyleAutoDetect
+	if c.ClientSecret == "" {
+		authStyle = oauth2.AuthStyleInParams
+	}
 	return &Provider{
 		conf: &oauth2.Config{
 			ClientID:     c.ClientID,
 			ClientSecret: c.ClientSecret,
 			Scopes:       c.Scopes,
 			Endpoint: oauth2.Endpoint{
-				AuthURL:  authURL,
-				TokenURL: tokenURL,
+				AuthURL:   authURL,
+				TokenURL:  tokenURL,
+				AuthStyle: authStyle,
 			},
 			RedirectURL: c.RedirectURL,
 		},
-		ctx: context.Background(),
+		ctx:            context.Background(),
+		base:           base,
+		transport:      transport,
+		observer:       c.Observer,
+		connectionsURL: c.ConnectionsURL,
+		revocationURL:  revocationURL,
+		dryRun:         c.DryRun,
+		issuer:         issuer,
+		jwks: &jwks{
+			url:    jwksURL,
+			ttl:    jwksTTL,
+			client: &http.Client{Transport: base},
+		},
 	}
 }
 
@@ -55,10 +204,15 @@ type XeroTransport struct {
 }
 
 // RoundTrip method will add on each request the custom header for inform the
-// tenantID
+// tenantID, requests that already carry the header or made without a tenant
+// are sent as they are
 func (xt *XeroTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	req.Header.Add(tenantIDHeader, xt.TenantID.String())
-	return xt.T.RoundTrip(req)
+	if xt.TenantID == uuid.Nil || req.Header.Get(tenantIDHeader) != "" {
+		return xt.T.RoundTrip(req)
+	}
+	r := req.Clone(req.Context())
+	r.Header.Set(tenantIDHeader, xt.TenantID.String())
+	return xt.T.RoundTrip(r)
 }
 
 // NewXeroTransport will build a new XeroTransport based on the given Tenant
@@ -87,20 +241,33 @@ func (c *Provider) GetAuthURL(state string) string {
 // GetTokenFromCode method will find the token with the given code, this method
 // should be called after a success callback received from auth process
 func (c *Provider) GetTokenFromCode(code string) (*oauth2.Token, error) {
-	return c.conf.Exchange(c.ctx, code)
+	return c.GetTokenFromCodeContext(c.ctx, code)
+}
+
+// GetTokenFromCodeContext is the same as GetTokenFromCode but the token
+// request is bound to the given context
+func (c *Provider) GetTokenFromCodeContext(ctx context.Context, code string) (*oauth2.Token, error) {
+	return c.conf.Exchange(ctx, code)
 }
 
 // Refresh method will refresh the given token
 func (c *Provider) Refresh(t *oauth2.Token) (*oauth2.Token, error) {
-	return c.conf.TokenSource(c.ctx, t).Token()
+	return c.RefreshContext(c.ctx, t)
+}
+
+// RefreshContext is the same as Refresh but the token request is bound to
+// the given context
+func (c *Provider) RefreshContext(ctx context.Context, t *oauth2.Token) (*oauth2.Token, error) {
+	return c.conf.TokenSource(ctx, t).Token()
 }
 
-// Client will build a custom http.Client for Xero
+// Client will build a custom http.Client for Xero, the context of each
+// request made with it is also used to refresh the token when needed
 func (c *Provider) Client(s *Session) *http.Client {
 	return &http.Client{
-		Transport: &oauth2.Transport{
-			Base:   NewXeroTransport(s.TenantID),
-			Source: oauth2.ReuseTokenSource(nil, NewTokenRefresher(s.Repo, s.Token, c, s.UserID)),
+		Transport: &TokenTransport{
+			Base:   &XeroTransport{T: c.transport, TenantID: s.TenantID},
+			Source: newTokenRefresher(s.Repo, s.Token, c, s.UserID),
 		},
 	}
 }
@@ -108,5 +275,11 @@ func (c *Provider) Client(s *Session) *http.Client {
 // NewClient method will return a new http.Client for use in our calls, using
 // the TokenSource will even refresh the token if needed
 func (c *Provider) NewClient(t *oauth2.Token) *http.Client {
-	return c.conf.Client(c.ctx, t)
+	return c.NewClientContext(c.ctx, t)
+}
+
+// NewClientContext is the same as NewClient but the token refreshes are
+// bound to the given context, the client stops working once it's done
+func (c *Provider) NewClientContext(ctx context.Context, t *oauth2.Token) *http.Client {
+	return c.conf.Client(ctx, t)
 }
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/connection"
+	"github.com/quickaco/xerosdk/helpers"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// clientCredentialsExpiryDelta is how long before its expiry a client
+// credentials token is replaced, so a request never carries a token that
+// expires on the way
+const clientCredentialsExpiryDelta = time.Minute
+
+// ClientCredentialsSource is a ContextTokenSource for the Xero custom
+// connections, it gets a token with the client credentials grant and keeps it
+// until it's about to expire. There is no user nor refresh token involved
+type ClientCredentialsSource struct {
+	mu       sync.Mutex
+	conf     clientcredentials.Config
+	token    *oauth2.Token
+	observer helpers.Observer
+}
+
+// Token method will return a valid token, getting a new one when needed
+func (s *ClientCredentialsSource) Token() (*oauth2.Token, error) {
+	return s.TokenContext(context.Background())
+}
+
+// TokenContext is the same as Token but the token request, when needed, is
+// bound to the given context
+func (s *ClientCredentialsSource) TokenContext(ctx context.Context) (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.valid() {
+		return s.token, nil
+	}
+	start := time.Now()
+	token, err := s.conf.Token(ctx)
+	if s.observer != nil {
+		s.observer.OnTokenRefresh(ctx, helpers.TokenRefreshInfo{
+			Duration: time.Since(start),
+			Err:      err,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	return token, nil
+}
+
+func (s *ClientCredentialsSource) valid() bool {
+	if s.token == nil || s.token.AccessToken == "" {
+		return false
+	}
+	return s.token.Expiry.IsZero() || time.Until(s.token.Expiry) > clientCredentialsExpiryDelta
+}
+
+// ClientCredentials method will return the token source of the custom
+// connection of the provider, the same source is shared by all the clients
+// so the token is only requested once. The client authenticates as on the
+// other grants, a public client sends its client_id in the body
+func (c *Provider) ClientCredentials() *ClientCredentialsSource {
+	c.clientCredentialsOnce.Do(func() {
+		c.clientCredentials = &ClientCredentialsSource{
+			conf: clientcredentials.Config{
+				ClientID:     c.conf.ClientID,
+				ClientSecret: c.conf.ClientSecret,
+				TokenURL:     c.conf.Endpoint.TokenURL,
+				Scopes:       c.conf.Scopes,
+				AuthStyle:    c.conf.Endpoint.AuthStyle,
+			},
+			observer: c.observer,
+		}
+	})
+	return c.clientCredentials
+}
+
+// ClientCredentialsClient method will build a http.Client for a Xero custom
+// connection. The tenant is the one connected to it, found with the
+// connections endpoint, and the token is renewed before it expires
+func (c *Provider) ClientCredentialsClient(ctx context.Context) (*http.Client, error) {
+	cl := &http.Client{
+		Transport: &TokenTransport{
+			Base:   c.transport,
+			Source: c.ClientCredentials(),
+		},
+	}
+	tenants, err := connection.NewService(cl, c.connectionsURL).Tenants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(tenants) != 1 {
+		return nil, fmt.Errorf("auth: a custom connection must have one tenant, found %d", len(tenants))
+	}
+	return c.ClientCredentialsClientForTenant(tenants[0].TenantID), nil
+}
+
+// ClientCredentialsClientForTenant method will build a http.Client for a Xero
+// custom connection and the given tenant, without asking the connections
+// endpoint
+func (c *Provider) ClientCredentialsClientForTenant(tenantID uuid.UUID) *http.Client {
+	return &http.Client{
+		Transport: &TokenTransport{
+			Base:   &XeroTransport{T: c.transport, TenantID: tenantID},
+			Source: c.ClientCredentials(),
+		},
+	}
+}
@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// clientCredentialsServer answers the token requests with tokens that expire
+// in expiresIn seconds, and keeps the forms and Authorization headers it got
+type clientCredentialsServer struct {
+	*httptest.Server
+
+	mu             sync.Mutex
+	forms          []url.Values
+	authorizations []string
+	expiresIn      int
+	fail           bool
+}
+
+func newClientCredentialsServer(expiresIn int) *clientCredentialsServer {
+	s := &clientCredentialsServer{expiresIn: expiresIn}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.forms = append(s.forms, r.PostForm)
+		s.authorizations = append(s.authorizations, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		if s.fail {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access-" + strconv.Itoa(len(s.forms)),
+			"token_type":   "Bearer",
+			"expires_in":   s.expiresIn,
+		})
+	}))
+	return s
+}
+
+func TestClientCredentialsForms(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		form      url.Values
+		basicAuth bool
+	}{
+		{
+			name:      "confidential client",
+			secret:    "secret",
+			form:      url.Values{"grant_type": {"client_credentials"}, "scope": {"accounting.transactions"}},
+			basicAuth: true,
+		},
+		{
+			name: "public client",
+			form: url.Values{"grant_type": {"client_credentials"}, "scope": {"accounting.transactions"}, "client_id": {"client"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newClientCredentialsServer(1800)
+			defer server.Close()
+			provider := NewProvider(Config{
+				ClientID:     "client",
+				ClientSecret: tt.secret,
+				TokenURL:     server.URL,
+				Scopes:       []string{"accounting.transactions"},
+			})
+			token, err := provider.ClientCredentials().TokenContext(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token.AccessToken != "access-1" || token.RefreshToken != "" {
+				t.Errorf("token = %+v, want access-1 without refresh token", token)
+			}
+			if len(server.forms) != 1 {
+				t.Fatalf("token requests = %d, want 1", len(server.forms))
+			}
+			if got := server.forms[0].Encode(); got != tt.form.Encode() {
+				t.Errorf("form = %s, want %s", got, tt.form.Encode())
+			}
+			if hasAuth := strings.HasPrefix(server.authorizations[0], "Basic "); hasAuth != tt.basicAuth {
+				t.Errorf("Authorization = %q, want basic auth %v", server.authorizations[0], tt.basicAuth)
+			}
+		})
+	}
+}
+
+func TestClientCredentialsRenewal(t *testing.T) {
+	tests := []struct {
+		name      string
+		expiresIn int
+		requests  int
+	}{
+		{name: "kept until it expires", expiresIn: 1800, requests: 1},
+		{name: "renewed within the expiry delta", expiresIn: 30, requests: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newClientCredentialsServer(tt.expiresIn)
+			defer server.Close()
+			provider := NewProvider(Config{ClientID: "client", ClientSecret: "secret", TokenURL: server.URL})
+			source := provider.ClientCredentials()
+			if provider.ClientCredentials() != source {
+				t.Error("the provider built two token sources")
+			}
+			for i := 0; i < 3; i++ {
+				if _, err := source.TokenContext(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if len(server.forms) != tt.requests {
+				t.Errorf("token requests = %d, want %d", len(server.forms), tt.requests)
+			}
+		})
+	}
+}
+
+func TestClientCredentialsError(t *testing.T) {
+	server := newClientCredentialsServer(1800)
+	defer server.Close()
+	server.fail = true
+	provider := NewProvider(Config{ClientID: "client", ClientSecret: "secret", TokenURL: server.URL})
+	if _, err := provider.ClientCredentials().TokenContext(context.Background()); err == nil || !strings.Contains(err.Error(), "invalid_client") {
+		t.Errorf("TokenContext = %v, want invalid_client", err)
+	}
+}
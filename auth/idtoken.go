@@ -0,0 +1,289 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/helpers"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// DefaultIssuer is the issuer of the ID tokens signed by Xero
+	DefaultIssuer = "https://identity.xero.com"
+	// DefaultJWKSURL is the URL of the keys Xero signs the ID tokens with
+	DefaultJWKSURL = "https://identity.xero.com/.well-known/openid-configuration/jwks"
+	// DefaultJWKSCacheTTL is how long the keys are kept before asking for them
+	// again
+	DefaultJWKSCacheTTL = time.Hour
+
+	idTokenField = "id_token"
+	nonceParam   = "nonce"
+
+	// idTokenLeeway is the clock skew allowed when checking the times of an
+	// ID token
+	idTokenLeeway = time.Minute
+
+	// jwksRefetchInterval is the least time between two requests of the keys,
+	// so the tokens signed with unknown keys can't make each check call Xero
+	jwksRefetchInterval = time.Minute
+)
+
+var (
+	// ErrNoIDToken is returned when the token carries no ID token, the openid
+	// scope must be asked to get one
+	ErrNoIDToken = errors.New("auth: no id_token in the token")
+
+	// ErrInvalidIDToken is returned, wrapped with the reason, when an ID token
+	// is malformed, not signed by Xero or not meant for the provider
+	ErrInvalidIDToken = errors.New("auth: invalid id_token")
+
+	// ErrIDTokenExpired is returned when an ID token has expired
+	ErrIDTokenExpired = errors.New("auth: id_token expired")
+)
+
+// IDTokenClaims keeps the claims of a validated ID token, XeroUserID is the
+// Xero user who authorized the access and can be used as Session.UserID
+type IDTokenClaims struct {
+	Issuer          string
+	Subject         string
+	Audience        []string
+	Nonce           string
+	IssuedAt        time.Time
+	ExpiresAt       time.Time
+	XeroUserID      uuid.UUID
+	GlobalSessionID string
+	Email           string
+	GivenName       string
+	FamilyName      string
+}
+
+type idTokenHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+type idTokenPayload struct {
+	Issuer          string    `json:"iss"`
+	Subject         string    `json:"sub"`
+	Audience        audience  `json:"aud"`
+	Nonce           string    `json:"nonce"`
+	IssuedAt        int64     `json:"iat"`
+	ExpiresAt       int64     `json:"exp"`
+	XeroUserID      uuid.UUID `json:"xero_userid"`
+	GlobalSessionID string    `json:"global_session_id"`
+	Email           string    `json:"email"`
+	GivenName       string    `json:"given_name"`
+	FamilyName      string    `json:"family_name"`
+}
+
+// audience reads the aud claim, which is either a string or an array
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+func (a audience) contains(value string) bool {
+	for _, aud := range a {
+		if aud == value {
+			return true
+		}
+	}
+	return false
+}
+
+// jwks keeps the keys of a JSON Web Key Set, they are asked again once the
+// TTL is over or when a token is signed with an unknown key, at most once per
+// jwksRefetchInterval. They're asked with the base transport, as the token
+// requests
+type jwks struct {
+	url    string
+	ttl    time.Duration
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetched   time.Time
+	requested time.Time
+}
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+}
+
+func (j *jwks) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	key, ok := j.keys[kid]
+	if (!ok || time.Since(j.fetched) >= j.ttl) && time.Since(j.requested) >= jwksRefetchInterval {
+		j.requested = time.Now()
+		// A failed request keeps the cached keys, a token signed with one of
+		// them can still be checked
+		if err := j.fetch(ctx); err != nil && !ok {
+			return nil, err
+		}
+		key, ok = j.keys[kid]
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidIDToken, kid)
+	}
+	return key, nil
+}
+
+func (j *jwks) fetch(ctx context.Context) error {
+	body, err := helpers.FindContext(ctx, j.client, j.url, nil, nil)
+	if err != nil {
+		return err
+	}
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(body, &set); err != nil {
+		return err
+	}
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.KeyType != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return fmt.Errorf("auth: invalid key %q: %v", k.KeyID, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return fmt.Errorf("auth: invalid key %q: %v", k.KeyID, err)
+		}
+		keys[k.KeyID] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	j.keys = keys
+	j.fetched = time.Now()
+	return nil
+}
+
+// NewNonce function will return a new random nonce, it must be kept, e.g. in
+// the session of the user, to validate the ID token of the callback
+func NewNonce() (string, error) {
+	b := make([]byte, nonceBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// GetAuthURLWithNonce method will return the url for redirect and start the
+// OAuth2 process asking for the given nonce to be put in the ID token
+func (c *Provider) GetAuthURLWithNonce(state string, nonce string) string {
+	return c.conf.AuthCodeURL(state, oauth2.SetAuthURLParam(nonceParam, nonce))
+}
+
+// IDToken method will validate the ID token carried by the given token and
+// return its claims, see VerifyIDToken
+func (c *Provider) IDToken(ctx context.Context, t *oauth2.Token, nonce string) (*IDTokenClaims, error) {
+	raw, _ := t.Extra(idTokenField).(string)
+	if raw == "" {
+		return nil, ErrNoIDToken
+	}
+	return c.VerifyIDToken(ctx, raw, nonce)
+}
+
+// VerifyIDToken method will check the signature of the given ID token against
+// the Xero keys, its issuer, audience and expiry, and that its nonce is the
+// given one. An empty nonce only accepts the tokens without a nonce
+func (c *Provider) VerifyIDToken(ctx context.Context, raw string, nonce string) (*IDTokenClaims, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidIDToken)
+	}
+	var header idTokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if header.Algorithm != "RS256" {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidIDToken, header.Algorithm)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidIDToken)
+	}
+	key, err := c.jwks.key(ctx, header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidIDToken)
+	}
+
+	var payload idTokenPayload
+	if err := decodeSegment(parts[1], &payload); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	switch {
+	case payload.Issuer != c.issuer:
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidIDToken, payload.Issuer)
+	case !payload.Audience.contains(c.conf.ClientID):
+		return nil, fmt.Errorf("%w: not issued for this client", ErrInvalidIDToken)
+	case payload.Nonce != nonce:
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	case time.Unix(payload.IssuedAt, 0).After(now.Add(idTokenLeeway)):
+		return nil, fmt.Errorf("%w: issued in the future", ErrInvalidIDToken)
+	case !time.Unix(payload.ExpiresAt, 0).After(now.Add(-idTokenLeeway)):
+		return nil, ErrIDTokenExpired
+	}
+	return &IDTokenClaims{
+		Issuer:          payload.Issuer,
+		Subject:         payload.Subject,
+		Audience:        payload.Audience,
+		Nonce:           payload.Nonce,
+		IssuedAt:        time.Unix(payload.IssuedAt, 0),
+		ExpiresAt:       time.Unix(payload.ExpiresAt, 0),
+		XeroUserID:      payload.XeroUserID,
+		GlobalSessionID: payload.GlobalSessionID,
+		Email:           payload.Email,
+		GivenName:       payload.GivenName,
+		FamilyName:      payload.FamilyName,
+	}, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("%w: malformed segment", ErrInvalidIDToken)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	return nil
+}
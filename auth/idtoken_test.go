@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// jwksServer serves the public part of key as kid, it fails while failing
+// is not zero
+type jwksServer struct {
+	*httptest.Server
+	requests int32
+	failing  int32
+}
+
+func newJWKSServer(key *rsa.PrivateKey, kid string) *jwksServer {
+	s := &jwksServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.requests, 1)
+		if atomic.LoadInt32(&s.failing) != 0 {
+			http.Error(w, "{}", http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": kid,
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	return s
+}
+
+// signIDToken will build an ID token with the given header and claims signed
+// by key
+func signIDToken(t *testing.T, key *rsa.PrivateKey, header map[string]string, claims map[string]interface{}) string {
+	h, err := json.Marshal(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"iss":         DefaultIssuer,
+		"sub":         "subject",
+		"aud":         "client",
+		"nonce":       "nonce",
+		"iat":         time.Now().Unix(),
+		"exp":         time.Now().Add(time.Hour).Unix(),
+		"xero_userid": "6f7b3f4e-4b5a-4a8e-9a52-1c8f0e0d5b3a",
+		"email":       "user@example.com",
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newJWKSServer(key, "key-1")
+	defer server.Close()
+	provider := NewProvider(Config{ClientID: "client", JWKSURL: server.URL})
+
+	rs256 := map[string]string{"alg": "RS256", "kid": "key-1"}
+	with := func(name string, value interface{}) map[string]interface{} {
+		claims := validClaims()
+		claims[name] = value
+		return claims
+	}
+	tests := []struct {
+		name  string
+		token string
+		nonce string
+		want  error
+	}{
+		{"valid", signIDToken(t, key, rs256, validClaims()), "nonce", nil},
+		{"audience in an array", signIDToken(t, key, rs256, with("aud", []string{"other", "client"})), "nonce", nil},
+		{"bad signature", signIDToken(t, otherKey, rs256, validClaims()), "nonce", ErrInvalidIDToken},
+		{"wrong alg", signIDToken(t, key, map[string]string{"alg": "HS256", "kid": "key-1"}, validClaims()), "nonce", ErrInvalidIDToken},
+		{"unknown key", signIDToken(t, key, map[string]string{"alg": "RS256", "kid": "key-2"}, validClaims()), "nonce", ErrInvalidIDToken},
+		{"wrong issuer", signIDToken(t, key, rs256, with("iss", "https://example.com")), "nonce", ErrInvalidIDToken},
+		{"wrong audience", signIDToken(t, key, rs256, with("aud", "other")), "nonce", ErrInvalidIDToken},
+		{"expired", signIDToken(t, key, rs256, with("exp", time.Now().Add(-time.Hour).Unix())), "nonce", ErrIDTokenExpired},
+		{"issued in the future", signIDToken(t, key, rs256, with("iat", time.Now().Add(time.Hour).Unix())), "nonce", ErrInvalidIDToken},
+		{"nonce mismatch", signIDToken(t, key, rs256, validClaims()), "other", ErrInvalidIDToken},
+		{"nonce not expected", signIDToken(t, key, rs256, validClaims()), "", ErrInvalidIDToken},
+		{"nonce missing", signIDToken(t, key, rs256, with("nonce", "")), "nonce", ErrInvalidIDToken},
+		{"no nonce", signIDToken(t, key, rs256, with("nonce", "")), "", nil},
+		{"malformed", "header.payload", "nonce", ErrInvalidIDToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := provider.VerifyIDToken(context.Background(), tt.token, tt.nonce)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("VerifyIDToken = %v, want %v", err, tt.want)
+			}
+			if err == nil && (claims.Subject != "subject" || claims.Email != "user@example.com" || claims.XeroUserID.String() != "6f7b3f4e-4b5a-4a8e-9a52-1c8f0e0d5b3a") {
+				t.Errorf("claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestIDTokenMissing(t *testing.T) {
+	provider := NewProvider(Config{ClientID: "client"})
+	if _, err := provider.IDToken(context.Background(), &oauth2.Token{AccessToken: "access"}, ""); err != ErrNoIDToken {
+		t.Errorf("IDToken = %v, want ErrNoIDToken", err)
+	}
+}
+
+func TestJWKSRefetch(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newJWKSServer(key, "key-1")
+	defer server.Close()
+	provider := NewProvider(Config{ClientID: "client", JWKSURL: server.URL})
+	valid := signIDToken(t, key, map[string]string{"alg": "RS256", "kid": "key-1"}, validClaims())
+	unknown := signIDToken(t, key, map[string]string{"alg": "RS256", "kid": "key-2"}, validClaims())
+	ctx := context.Background()
+
+	if _, err := provider.VerifyIDToken(ctx, valid, "nonce"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := provider.VerifyIDToken(ctx, unknown, "nonce"); !errors.Is(err, ErrInvalidIDToken) {
+			t.Errorf("VerifyIDToken with an unknown key = %v, want ErrInvalidIDToken", err)
+		}
+	}
+	if server.requests != 1 {
+		t.Errorf("requests = %d, want 1 within the refetch interval", server.requests)
+	}
+
+	// Once the TTL is over a failed request keeps the cached key
+	provider.jwks.fetched = time.Now().Add(-2 * DefaultJWKSCacheTTL)
+	provider.jwks.requested = time.Now().Add(-2 * jwksRefetchInterval)
+	atomic.StoreInt32(&server.failing, 1)
+	if _, err := provider.VerifyIDToken(ctx, valid, "nonce"); err != nil {
+		t.Errorf("VerifyIDToken with a failed refetch = %v, want the cached key used", err)
+	}
+	provider.jwks.requested = time.Now().Add(-2 * jwksRefetchInterval)
+	if _, err := provider.VerifyIDToken(ctx, unknown, "nonce"); err == nil || errors.Is(err, ErrInvalidIDToken) {
+		t.Errorf("VerifyIDToken with an unknown key and a failed refetch = %v, want the request error", err)
+	}
+	if server.requests != 3 {
+		t.Errorf("requests = %d, want 3", server.requests)
+	}
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	// PKCEMethodS256 is the only code challenge method supported by Xero
+	PKCEMethodS256 = "S256"
+
+	codeChallengeParam       = "code_challenge"
+	codeChallengeMethodParam = "code_challenge_method"
+	codeVerifierParam        = "code_verifier"
+
+	// verifierBytes gives a verifier of 43 characters once encoded, the
+	// minimum allowed by RFC 7636
+	verifierBytes = 32
+)
+
+// PKCE keeps the code verifier of an authorization made with PKCE and its
+// challenge. The verifier must be kept, e.g. in the session of the user,
+// until the code is exchanged
+type PKCE struct {
+	Verifier  string
+	Challenge string
+	Method    string
+}
+
+// NewPKCE function will build a PKCE with a new random verifier and its S256
+// challenge
+func NewPKCE() (*PKCE, error) {
+	verifier, err := NewCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+	return &PKCE{
+		Verifier:  verifier,
+		Challenge: CodeChallengeS256(verifier),
+		Method:    PKCEMethodS256,
+	}, nil
+}
+
+// NewCodeVerifier function will return a new random code verifier
+func NewCodeVerifier() (string, error) {
+	b := make([]byte, verifierBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallengeS256 function will return the S256 challenge of the given
+// verifier
+func CodeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// GetAuthURLWithPKCE method will return the url for redirect and start the
+// OAuth2 process with the challenge of the given PKCE
+func (c *Provider) GetAuthURLWithPKCE(state string, pkce *PKCE) string {
+	method := pkce.Method
+	if method == "" {
+		method = PKCEMethodS256
+	}
+	return c.conf.AuthCodeURL(state,
+		oauth2.SetAuthURLParam(codeChallengeParam, pkce.Challenge),
+		oauth2.SetAuthURLParam(codeChallengeMethodParam, method),
+	)
+}
+
+// GetTokenFromCodeWithPKCE method will find the token with the given code
+// sending the verifier of the authorization, the client secret is not needed
+func (c *Provider) GetTokenFromCodeWithPKCE(code string, verifier string) (*oauth2.Token, error) {
+	return c.GetTokenFromCodeWithPKCEContext(c.ctx, code, verifier)
+}
+
+// GetTokenFromCodeWithPKCEContext is the same as GetTokenFromCodeWithPKCE but
+// the token request is bound to the given context
+func (c *Provider) GetTokenFromCodeWithPKCEContext(ctx context.Context, code string, verifier string) (*oauth2.Token, error) {
+	return c.conf.Exchange(ctx, code, oauth2.SetAuthURLParam(codeVerifierParam, verifier))
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestCodeChallengeS256(t *testing.T) {
+	// RFC 7636, Appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	if got := CodeChallengeS256(verifier); got != want {
+		t.Errorf("CodeChallengeS256 = %q, want %q", got, want)
+	}
+}
+
+func TestNewPKCE(t *testing.T) {
+	pkce, err := NewPKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkce.Verifier) != 43 {
+		t.Errorf("verifier length = %d, want 43", len(pkce.Verifier))
+	}
+	if pkce.Challenge != CodeChallengeS256(pkce.Verifier) || pkce.Method != PKCEMethodS256 {
+		t.Errorf("PKCE = %+v, want the S256 challenge of its verifier", pkce)
+	}
+	other, err := NewPKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Verifier == pkce.Verifier {
+		t.Error("two PKCE got the same verifier")
+	}
+}
+
+func TestGetAuthURLWithPKCE(t *testing.T) {
+	provider := NewProvider(Config{ClientID: "client", RedirectURL: "https://example.com/callback"})
+	authURL, err := url.Parse(provider.GetAuthURLWithPKCE("state", &PKCE{Challenge: "challenge"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := authURL.Query()
+	if query.Get(codeChallengeParam) != "challenge" || query.Get(codeChallengeMethodParam) != PKCEMethodS256 {
+		t.Errorf("auth URL query = %v, want the challenge with S256", query)
+	}
+}
+
+func TestGetTokenFromCodeWithPKCE(t *testing.T) {
+	var form url.Values
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		form, authorization = r.PostForm, r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "access",
+			"refresh_token": "refresh",
+			"token_type":    "Bearer",
+			"expires_in":    1800,
+		})
+	}))
+	defer server.Close()
+
+	provider := NewProvider(Config{
+		ClientID:    "client",
+		RedirectURL: "https://example.com/callback",
+		TokenURL:    server.URL,
+	})
+	token, err := provider.GetTokenFromCodeWithPKCEContext(context.Background(), "code", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "access" {
+		t.Errorf("access token = %q, want access", token.AccessToken)
+	}
+	if form.Get("grant_type") != "authorization_code" || form.Get("code") != "code" {
+		t.Errorf("form = %v, want the authorization code grant", form)
+	}
+	if form.Get(codeVerifierParam) != "verifier" || form.Get("client_id") != "client" {
+		t.Errorf("form = %v, want the verifier and the client_id", form)
+	}
+	if _, ok := form["client_secret"]; ok || authorization != "" {
+		t.Errorf("form = %v, Authorization = %q, want no secret", form, authorization)
+	}
+}
@@ -1,7 +1,12 @@
 package auth
 
 import (
+	"context"
+	"sync"
+	"time"
+
 	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/helpers"
 	"golang.org/x/oauth2"
 )
 
@@ -11,10 +16,13 @@ type Repository interface {
 	CreateSession(userID uuid.UUID, t *oauth2.Token) error
 	UpdateSession(userID uuid.UUID, t *oauth2.Token) error
 	GetSession(userID uuid.UUID) (*oauth2.Token, error)
+	DeleteSession(userID uuid.UUID) error
 }
 
-// TokenRefresher keep the information needed for our custom TokenSource
+// TokenRefresher keep the information needed for our custom TokenSource, it's
+// safe for concurrent use
 type TokenRefresher struct {
+	mu       sync.Mutex
 	repo     Repository
 	token    *oauth2.Token
 	provider *Provider
@@ -24,6 +32,10 @@ type TokenRefresher struct {
 // NewTokenRefresher function will build a new TokenSource based on a given token
 // and a given repository
 func NewTokenRefresher(repo Repository, token *oauth2.Token, provider *Provider, userID uuid.UUID) oauth2.TokenSource {
+	return newTokenRefresher(repo, token, provider, userID)
+}
+
+func newTokenRefresher(repo Repository, token *oauth2.Token, provider *Provider, userID uuid.UUID) *TokenRefresher {
 	return &TokenRefresher{
 		repo:     repo,
 		token:    token,
@@ -35,15 +47,84 @@ func NewTokenRefresher(repo Repository, token *oauth2.Token, provider *Provider,
 // Token method is the custom implementation of the refresh token process using
 // a session repo as a base
 func (t *TokenRefresher) Token() (*oauth2.Token, error) {
-	if !t.token.Valid() {
-		token, err := t.provider.Refresh(t.token)
-		if err != nil {
-			return nil, err
+	return t.TokenContext(context.Background())
+}
+
+// TokenContext is the same as Token but the refresh request, when needed, is
+// bound to the given context. Xero refresh tokens can only be used once, so
+// the refreshes of a user are made one at a time across all the clients of the
+// provider, and the session is read again before refreshing in case another
+// client already did it
+func (t *TokenRefresher) TokenContext(ctx context.Context) (*oauth2.Token, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.token.Valid() {
+		return t.token, nil
+	}
+
+	unlock := t.provider.refreshLocks.lock(t.userID)
+	defer unlock()
+	if latest, err := t.repo.GetSession(t.userID); err == nil && latest != nil {
+		t.token = latest
+		if latest.Valid() {
+			return latest, nil
 		}
-		if err = t.repo.UpdateSession(t.userID, token); err != nil {
-			return nil, err
+	}
+
+	start := time.Now()
+	token, err := t.provider.RefreshContext(ctx, t.token)
+	if t.provider.observer != nil {
+		t.provider.observer.OnTokenRefresh(ctx, helpers.TokenRefreshInfo{
+			UserID:   t.userID.String(),
+			Duration: time.Since(start),
+			Err:      err,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	// The refresh token sent is no longer valid, the new one is kept even
+	// when it can't be stored so the next call doesn't send the old one
+	t.token = token
+	if err = t.repo.UpdateSession(t.userID, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// userLocks keeps a lock per user, which is dropped once nobody holds or
+// waits for it
+type userLocks struct {
+	mu    sync.Mutex
+	locks map[uuid.UUID]*userLock
+}
+
+type userLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock will lock the given user, the returned function unlocks it
+func (l *userLocks) lock(userID uuid.UUID) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[uuid.UUID]*userLock)
+	}
+	ul, ok := l.locks[userID]
+	if !ok {
+		ul = &userLock{}
+		l.locks[userID] = ul
+	}
+	ul.refs++
+	l.mu.Unlock()
+
+	ul.Lock()
+	return func() {
+		ul.Unlock()
+		l.mu.Lock()
+		if ul.refs--; ul.refs == 0 {
+			delete(l.locks, userID)
 		}
-		return token, nil
+		l.mu.Unlock()
 	}
-	return t.token, nil
 }
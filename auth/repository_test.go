@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"golang.org/x/oauth2"
+)
+
+// memoryRepository keeps the sessions in memory, UpdateSession fails with
+// updateErr when it's set
+type memoryRepository struct {
+	mu        sync.Mutex
+	sessions  map[uuid.UUID]*oauth2.Token
+	updateErr error
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{sessions: make(map[uuid.UUID]*oauth2.Token)}
+}
+
+func (r *memoryRepository) CreateSession(userID uuid.UUID, t *oauth2.Token) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[userID] = t
+	return nil
+}
+
+func (r *memoryRepository) UpdateSession(userID uuid.UUID, t *oauth2.Token) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.updateErr != nil {
+		return r.updateErr
+	}
+	r.sessions[userID] = t
+	return nil
+}
+
+func (r *memoryRepository) GetSession(userID uuid.UUID) (*oauth2.Token, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sessions[userID], nil
+}
+
+func (r *memoryRepository) DeleteSession(userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, userID)
+	return nil
+}
+
+// tokenServer rotates the refresh token on each refresh, as Xero does, and
+// rejects the refresh tokens already used
+func tokenServer(refreshes *int32) *httptest.Server {
+	var mu sync.Mutex
+	current := "refresh-1"
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if r.PostForm.Get("refresh_token") != current {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		n := atomic.AddInt32(refreshes, 1)
+		current = "refresh-" + strconv.Itoa(int(n)+1)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "access-" + strconv.Itoa(int(n)+1),
+			"refresh_token": current,
+			"token_type":    "Bearer",
+			"expires_in":    1800,
+		})
+	}))
+}
+
+func expiredToken() *oauth2.Token {
+	return &oauth2.Token{
+		AccessToken:  "access-1",
+		RefreshToken: "refresh-1",
+		TokenType:    "Bearer",
+		Expiry:       time.Now().Add(-time.Minute),
+	}
+}
+
+func TestTokenRefresherConcurrentClients(t *testing.T) {
+	var refreshes int32
+	tokens := tokenServer(&refreshes)
+	defer tokens.Close()
+	var mu sync.Mutex
+	authorizations := make(map[string]int)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authorizations[r.Header.Get("Authorization")]++
+		mu.Unlock()
+	}))
+	defer api.Close()
+
+	provider := NewProvider(Config{ClientID: "client", ClientSecret: "secret", TokenURL: tokens.URL})
+	repo := newMemoryRepository()
+	userID := uuid.Must(uuid.NewV4())
+	repo.CreateSession(userID, expiredToken())
+
+	const clients, calls = 4, 8
+	var wg sync.WaitGroup
+	for i := 0; i < clients; i++ {
+		// Each client starts from the session as it was read on its request
+		cl := provider.Client(&Session{Token: expiredToken(), UserID: userID, Repo: repo})
+		for j := 0; j < calls; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := cl.Get(api.URL)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				resp.Body.Close()
+			}()
+		}
+	}
+	wg.Wait()
+
+	if refreshes != 1 {
+		t.Errorf("refreshes = %d, want 1", refreshes)
+	}
+	if authorizations["Bearer access-2"] != clients*calls {
+		t.Errorf("authorizations = %v, want %d with access-2", authorizations, clients*calls)
+	}
+	stored, _ := repo.GetSession(userID)
+	if stored.RefreshToken != "refresh-2" || stored.AccessToken != "access-2" {
+		t.Errorf("stored token = %s/%s, want access-2/refresh-2", stored.AccessToken, stored.RefreshToken)
+	}
+}
+
+func TestTokenRefresherUpdateSessionError(t *testing.T) {
+	var refreshes int32
+	tokens := tokenServer(&refreshes)
+	defer tokens.Close()
+
+	provider := NewProvider(Config{ClientID: "client", ClientSecret: "secret", TokenURL: tokens.URL})
+	repo := newMemoryRepository()
+	userID := uuid.Must(uuid.NewV4())
+	repo.CreateSession(userID, expiredToken())
+	repo.updateErr = errors.New("database down")
+
+	refresher := newTokenRefresher(repo, expiredToken(), provider, userID)
+	if _, err := refresher.TokenContext(context.Background()); err != repo.updateErr {
+		t.Fatalf("err = %v, want %v", err, repo.updateErr)
+	}
+	token, err := refresher.TokenContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.RefreshToken != "refresh-2" || refreshes != 1 {
+		t.Errorf("token = %s after %d refreshes, want refresh-2 after 1", token.RefreshToken, refreshes)
+	}
+}
@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/connection"
+	"github.com/quickaco/xerosdk/helpers"
+	"golang.org/x/oauth2"
+)
+
+// DefaultRevocationURL is the URL of the Xero token revocation endpoint
+const DefaultRevocationURL = "https://identity.xero.com/connect/revocation"
+
+// redactedToken replaces the token of a revocation captured by a dry run, so
+// the plan doesn't carry it
+const redactedToken = "[REDACTED]"
+
+var (
+	// ErrNoSession is returned by Disconnect when the repository has no
+	// session for the user
+	ErrNoSession = errors.New("auth: no session for the user")
+
+	// ErrNoToken is returned by Revoke when it's given no token to revoke
+	ErrNoToken = errors.New("auth: no token to revoke")
+)
+
+// Revoke method will revoke the given token at Xero, its refresh token when
+// it has one. Revoking the refresh token also invalidates its access tokens
+func (c *Provider) Revoke(t *oauth2.Token) error {
+	return c.RevokeContext(c.ctx, t)
+}
+
+// RevokeContext is the same as Revoke but the revocation request is bound to
+// the given context. Like the token requests, it's sent with the base
+// transport, so it's never held or retried. With the DryRun of the Config it's
+// captured in the plan, with the token redacted, instead of being sent
+func (c *Provider) RevokeContext(ctx context.Context, t *oauth2.Token) error {
+	if t == nil || (t.RefreshToken == "" && t.AccessToken == "") {
+		return ErrNoToken
+	}
+	form := url.Values{}
+	if t.RefreshToken != "" {
+		form.Set("token", t.RefreshToken)
+		form.Set("token_type_hint", "refresh_token")
+	} else {
+		form.Set("token", t.AccessToken)
+		form.Set("token_type_hint", "access_token")
+	}
+	transport := c.base
+	if c.dryRun != nil {
+		form.Set("token", redactedToken)
+		transport = helpers.NewDryRunTransport(c.base, c.dryRun)
+		ctx = helpers.WithOperation(ctx, helpers.Operation{Resource: "Tokens", Name: "Revoke"})
+	}
+	// Public clients have no secret and send their client_id in the body
+	if c.conf.ClientSecret == "" {
+		form.Set("client_id", c.conf.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.revocationURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if c.conf.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.conf.ClientID), url.QueryEscape(c.conf.ClientSecret))
+	}
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("auth: revocation failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// TenantDisconnect is the outcome of removing one connection on Disconnect
+type TenantDisconnect struct {
+	Tenant connection.Tenant
+	Err    error
+}
+
+// DisconnectReport tells what Disconnect cleaned up. Each step keeps its own
+// error, a failed step stops the following ones
+type DisconnectReport struct {
+	UserID uuid.UUID
+
+	// Tenants are the connections found for the user, TenantsErr is set when
+	// they couldn't be listed
+	Tenants    []TenantDisconnect
+	TenantsErr error
+
+	// Revoked is also set when the revocation was captured by a dry run
+	Revoked   bool
+	RevokeErr error
+
+	SessionDeleted bool
+	SessionErr     error
+}
+
+// Err method will return the first error found by Disconnect, nil when
+// everything was cleaned up
+func (r *DisconnectReport) Err() error {
+	if r.TenantsErr != nil {
+		return r.TenantsErr
+	}
+	for _, tenant := range r.Tenants {
+		if tenant.Err != nil {
+			return tenant.Err
+		}
+	}
+	if r.RevokeErr != nil {
+		return r.RevokeErr
+	}
+	return r.SessionErr
+}
+
+// Disconnect method will remove every connection of the user, revoke its
+// token at Xero and delete its session from the repository, in that order.
+// Each step only runs when the previous ones succeeded, so the token is kept
+// while a connection couldn't be listed or removed, and the session while the
+// token couldn't be revoked, and Disconnect can be run again. With the DryRun
+// of the Config the removals and the revocation are captured in the plan and
+// the session is kept. The report tells what was cleaned up, the error is its
+// Err
+func (c *Provider) Disconnect(ctx context.Context, repo Repository, userID uuid.UUID) (*DisconnectReport, error) {
+	report := &DisconnectReport{UserID: userID}
+	token, err := repo.GetSession(userID)
+	if err != nil {
+		report.SessionErr = err
+		return report, err
+	}
+	if token == nil {
+		report.SessionErr = ErrNoSession
+		return report, ErrNoSession
+	}
+
+	source := newTokenRefresher(repo, token, c, userID)
+	cl := &http.Client{
+		Transport: &TokenTransport{
+			Base:   c.transport,
+			Source: source,
+		},
+	}
+	connections := connection.NewService(cl, c.connectionsURL)
+	tenants, err := connections.Tenants(ctx)
+	report.TenantsErr = err
+	for _, tenant := range tenants {
+		report.Tenants = append(report.Tenants, TenantDisconnect{
+			Tenant: tenant,
+			Err:    connections.Delete(ctx, tenant.ID),
+		})
+	}
+
+	if err := report.Err(); err != nil {
+		return report, err
+	}
+
+	// The token may have been refreshed to list the connections
+	if report.RevokeErr = c.RevokeContext(ctx, source.token); report.RevokeErr != nil {
+		return report, report.Err()
+	}
+	report.Revoked = true
+	if c.dryRun != nil {
+		return report, nil
+	}
+
+	if report.SessionErr = repo.DeleteSession(userID); report.SessionErr == nil {
+		report.SessionDeleted = true
+	}
+	return report, report.Err()
+}
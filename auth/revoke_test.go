@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/helpers"
+	"golang.org/x/oauth2"
+)
+
+// xeroServer answers the revocations and the connections calls, the fail
+// fields make the matching calls fail
+type xeroServer struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	revocations []url.Values
+	basicAuth   []string
+	deleted     []string
+
+	failList   bool
+	failDelete bool
+	failRevoke bool
+	tenants    []string
+}
+
+func newXeroServer(tenants ...string) *xeroServer {
+	s := &xeroServer{tenants: tenants}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+func (s *xeroServer) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case r.URL.Path == "/revoke":
+		r.ParseForm()
+		s.revocations = append(s.revocations, r.PostForm)
+		s.basicAuth = append(s.basicAuth, r.Header.Get("Authorization"))
+		if s.failRevoke {
+			http.Error(w, `{"error":"invalid_client"}`, http.StatusBadRequest)
+		}
+	case r.URL.Path == "/connections" && r.Method == http.MethodGet:
+		if s.failList {
+			http.Error(w, "{}", http.StatusInternalServerError)
+			return
+		}
+		var tenants []map[string]string
+		for _, id := range s.tenants {
+			tenants = append(tenants, map[string]string{"id": id, "tenantType": "ORGANISATION"})
+		}
+		json.NewEncoder(w).Encode(tenants)
+	case strings.HasPrefix(r.URL.Path, "/connections/") && r.Method == http.MethodDelete:
+		if s.failDelete {
+			http.Error(w, "{}", http.StatusInternalServerError)
+			return
+		}
+		s.deleted = append(s.deleted, strings.TrimPrefix(r.URL.Path, "/connections/"))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *xeroServer) provider(secret string, plan *helpers.Plan) *Provider {
+	return NewProvider(Config{
+		ClientID:       "client",
+		ClientSecret:   secret,
+		RevocationURL:  s.URL + "/revoke",
+		ConnectionsURL: s.URL + "/connections",
+		DryRun:         plan,
+	})
+}
+
+func validToken() *oauth2.Token {
+	return &oauth2.Token{
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		TokenType:    "Bearer",
+		Expiry:       time.Now().Add(time.Hour),
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	server := newXeroServer()
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		secret    string
+		token     *oauth2.Token
+		form      url.Values
+		basicAuth bool
+	}{
+		{
+			name:      "confidential client",
+			secret:    "secret",
+			token:     validToken(),
+			form:      url.Values{"token": {"refresh"}, "token_type_hint": {"refresh_token"}},
+			basicAuth: true,
+		},
+		{
+			name:   "public client",
+			token:  validToken(),
+			form:   url.Values{"token": {"refresh"}, "token_type_hint": {"refresh_token"}, "client_id": {"client"}},
+			secret: "",
+		},
+		{
+			name:      "access token only",
+			secret:    "secret",
+			token:     &oauth2.Token{AccessToken: "access"},
+			form:      url.Values{"token": {"access"}, "token_type_hint": {"access_token"}},
+			basicAuth: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.revocations, server.basicAuth = nil, nil
+			if err := server.provider(tt.secret, nil).Revoke(tt.token); err != nil {
+				t.Fatal(err)
+			}
+			if len(server.revocations) != 1 {
+				t.Fatalf("revocations = %d, want 1", len(server.revocations))
+			}
+			if got := server.revocations[0].Encode(); got != tt.form.Encode() {
+				t.Errorf("form = %s, want %s", got, tt.form.Encode())
+			}
+			if hasAuth := strings.HasPrefix(server.basicAuth[0], "Basic "); hasAuth != tt.basicAuth {
+				t.Errorf("Authorization = %q, want basic auth %v", server.basicAuth[0], tt.basicAuth)
+			}
+		})
+	}
+}
+
+func TestRevokeErrors(t *testing.T) {
+	server := newXeroServer()
+	defer server.Close()
+	provider := server.provider("secret", nil)
+
+	for _, token := range []*oauth2.Token{nil, {}} {
+		if err := provider.Revoke(token); err != ErrNoToken {
+			t.Errorf("Revoke(%v) = %v, want ErrNoToken", token, err)
+		}
+	}
+	server.failRevoke = true
+	if err := provider.Revoke(validToken()); err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("Revoke = %v, want the status of the failure", err)
+	}
+}
+
+func TestDisconnect(t *testing.T) {
+	tenantID := uuid.Must(uuid.NewV4()).String()
+	tests := []struct {
+		name           string
+		failList       bool
+		failDelete     bool
+		failRevoke     bool
+		revoked        bool
+		sessionDeleted bool
+	}{
+		{name: "everything cleaned up", revoked: true, sessionDeleted: true},
+		{name: "listing failed", failList: true},
+		{name: "removal failed", failDelete: true},
+		{name: "revocation failed", failRevoke: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newXeroServer(tenantID)
+			defer server.Close()
+			server.failList, server.failDelete, server.failRevoke = tt.failList, tt.failDelete, tt.failRevoke
+			repo := newMemoryRepository()
+			userID := uuid.Must(uuid.NewV4())
+			repo.CreateSession(userID, validToken())
+
+			report, err := server.provider("secret", nil).Disconnect(context.Background(), repo, userID)
+			if wantErr := !tt.sessionDeleted; (err != nil) != wantErr {
+				t.Fatalf("err = %v, want error %v", err, wantErr)
+			}
+			if report.Revoked != tt.revoked || report.SessionDeleted != tt.sessionDeleted {
+				t.Errorf("report = %+v, want revoked %v and session deleted %v", report, tt.revoked, tt.sessionDeleted)
+			}
+			if revoked := len(server.revocations) > 0; revoked != (tt.revoked || tt.failRevoke) {
+				t.Errorf("revocations = %d", len(server.revocations))
+			}
+			if session, _ := repo.GetSession(userID); (session == nil) != tt.sessionDeleted {
+				t.Errorf("session = %v, want deleted %v", session, tt.sessionDeleted)
+			}
+		})
+	}
+}
+
+func TestDisconnectDryRun(t *testing.T) {
+	tenantID := uuid.Must(uuid.NewV4()).String()
+	server := newXeroServer(tenantID)
+	defer server.Close()
+	repo := newMemoryRepository()
+	userID := uuid.Must(uuid.NewV4())
+	repo.CreateSession(userID, validToken())
+	plan := helpers.NewPlan()
+
+	report, err := server.provider("secret", plan).Disconnect(context.Background(), repo, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Revoked || report.SessionDeleted {
+		t.Errorf("report = %+v, want revoked and the session kept", report)
+	}
+	if len(server.revocations) != 0 || len(server.deleted) != 0 {
+		t.Errorf("sent %d revocations and %d removals, want none", len(server.revocations), len(server.deleted))
+	}
+	if session, _ := repo.GetSession(userID); session == nil {
+		t.Error("session deleted on a dry run")
+	}
+
+	writes := plan.Writes()
+	if len(writes) != 2 {
+		t.Fatalf("plan = %d writes, want the removal and the revocation", len(writes))
+	}
+	if writes[0].Method != http.MethodDelete || !strings.HasSuffix(writes[0].URL, tenantID) {
+		t.Errorf("first write = %+v, want the removal of the connection", writes[0])
+	}
+	var body string
+	json.Unmarshal(writes[1].Body, &body)
+	if writes[1].Operation != "Tokens.Revoke" || body != "token=%5BREDACTED%5D&token_type_hint=refresh_token" {
+		t.Errorf("second write = %+v, want the revocation with the token redacted", writes[1])
+	}
+}
@@ -0,0 +1,238 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// DefaultStateTTL is how long a state built by StateManager is accepted when
+// no other TTL is given
+const DefaultStateTTL = 10 * time.Minute
+
+// MinStateKeyLength is the shortest key accepted by NewStateManager, the size
+// of a SHA-256 hash
+const MinStateKeyLength = 32
+
+const nonceBytes = 16
+
+var (
+	// ErrInvalidState is returned when the state of a callback was not built
+	// by the StateManager or belongs to another session
+	ErrInvalidState = errors.New("auth: invalid state")
+
+	// ErrStateExpired is returned when the state of a callback is older than
+	// the TTL of the StateManager
+	ErrStateExpired = errors.New("auth: state expired")
+
+	// ErrStateUsed is returned when the state of a callback was already
+	// accepted once by the StateManager
+	ErrStateUsed = errors.New("auth: state already used")
+
+	// ErrNoSessionID is returned when a state is built or verified without
+	// the session it's bound to
+	ErrNoSessionID = errors.New("auth: no session ID for the state")
+
+	// ErrMissingCode is returned when a callback carries neither a code nor
+	// an error
+	ErrMissingCode = errors.New("auth: missing code")
+)
+
+// State keeps the information bound to the state of an authorization
+type State struct {
+	SessionID string    `json:"s"`
+	ReturnURL string    `json:"r,omitempty"`
+	Nonce     string    `json:"n"`
+	ExpiresAt time.Time `json:"-"`
+}
+
+type statePayload struct {
+	State
+	Expiry int64 `json:"e"`
+}
+
+// StateManager builds and verifies the state of the authorizations. The
+// states are signed with HMAC-SHA256 and bound to the session of the user who
+// started the authorization. The nonces of the states verified are kept in
+// memory until they expire, so each state is accepted once by a manager; the
+// instances of a service that don't share the manager can each accept it once
+// within its TTL
+type StateManager struct {
+	key []byte
+	ttl time.Duration
+
+	mu   sync.Mutex
+	used map[string]time.Time
+}
+
+// NewStateManager function will build a StateManager that signs the states
+// with the given key, which must be random, kept secret and at least
+// MinStateKeyLength bytes long. When ttl is zero DefaultStateTTL is used
+func NewStateManager(key []byte, ttl time.Duration) (*StateManager, error) {
+	if len(key) < MinStateKeyLength {
+		return nil, fmt.Errorf("auth: the state key has %d bytes, it needs at least %d", len(key), MinStateKeyLength)
+	}
+	if ttl <= 0 {
+		ttl = DefaultStateTTL
+	}
+	return &StateManager{
+		key:  append([]byte(nil), key...),
+		ttl:  ttl,
+		used: make(map[string]time.Time),
+	}, nil
+}
+
+func (m *StateManager) sign(payload string) string {
+	mac := hmac.New(sha256.New, m.key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// New method will build a new state for the given session, returnURL is kept
+// in it to send the user back once the authorization is done. The session ID
+// can't be empty
+func (m *StateManager) New(sessionID string, returnURL string) (string, error) {
+	if sessionID == "" {
+		return "", ErrNoSessionID
+	}
+	nonce := make([]byte, nonceBytes)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(statePayload{
+		State: State{
+			SessionID: sessionID,
+			ReturnURL: returnURL,
+			Nonce:     base64.RawURLEncoding.EncodeToString(nonce),
+		},
+		Expiry: time.Now().Add(m.ttl).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + m.sign(encoded), nil
+}
+
+// Verify method will check that the given state was built by the manager for
+// the given session, has not expired and was not verified before
+func (m *StateManager) Verify(value string, sessionID string) (*State, error) {
+	if sessionID == "" {
+		return nil, ErrNoSessionID
+	}
+	parts := strings.Split(value, ".")
+	if len(parts) != 2 || !hmac.Equal([]byte(parts[1]), []byte(m.sign(parts[0]))) {
+		return nil, ErrInvalidState
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidState
+	}
+	var payload statePayload
+	if err := json.Unmarshal(decoded, &payload); err != nil {
+		return nil, ErrInvalidState
+	}
+	if subtle.ConstantTimeCompare([]byte(payload.SessionID), []byte(sessionID)) != 1 {
+		return nil, ErrInvalidState
+	}
+	state := payload.State
+	state.ExpiresAt = time.Unix(payload.Expiry, 0)
+	if time.Now().After(state.ExpiresAt) {
+		return nil, ErrStateExpired
+	}
+	if !m.use(state.Nonce, state.ExpiresAt) {
+		return nil, ErrStateUsed
+	}
+	return &state, nil
+}
+
+// use will mark the nonce of a state as used until the state expires, it
+// returns false when it already was. The expired nonces are dropped
+func (m *StateManager) use(nonce string, expiresAt time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for used, expiry := range m.used {
+		if now.After(expiry) {
+			delete(m.used, used)
+		}
+	}
+	if _, ok := m.used[nonce]; ok {
+		return false
+	}
+	m.used[nonce] = expiresAt
+	return true
+}
+
+// CallbackError is returned when Xero sends the user back with an error, such
+// as access_denied when the user didn't grant the access
+type CallbackError struct {
+	Code        string
+	Description string
+}
+
+func (e *CallbackError) Error() string {
+	if e.Description == "" {
+		return fmt.Sprintf("auth: authorization failed: %s", e.Code)
+	}
+	return fmt.Sprintf("auth: authorization failed: %s: %s", e.Code, e.Description)
+}
+
+// CallbackResult is the outcome of a successful authorization
+type CallbackResult struct {
+	Token *oauth2.Token
+	State *State
+}
+
+// HandleCallback method will handle the request Xero sends the user back
+// with. It checks the error sent by Xero and the state, which must belong to
+// the given session, and then exchanges the code for a token
+func (c *Provider) HandleCallback(r *http.Request, states *StateManager, sessionID string) (*CallbackResult, error) {
+	return c.handleCallback(r, states, sessionID, c.GetTokenFromCodeContext)
+}
+
+// HandleCallbackWithPKCE method is the same as HandleCallback but the code is
+// exchanged with the verifier of the authorization, see NewPKCE
+func (c *Provider) HandleCallbackWithPKCE(r *http.Request, states *StateManager, sessionID string, verifier string) (*CallbackResult, error) {
+	return c.handleCallback(r, states, sessionID, func(ctx context.Context, code string) (*oauth2.Token, error) {
+		return c.GetTokenFromCodeWithPKCEContext(ctx, code, verifier)
+	})
+}
+
+func (c *Provider) handleCallback(r *http.Request, states *StateManager, sessionID string, exchange func(ctx context.Context, code string) (*oauth2.Token, error)) (*CallbackResult, error) {
+	query := r.URL.Query()
+	if code := query.Get("error"); code != "" {
+		return nil, &CallbackError{
+			Code:        code,
+			Description: query.Get("error_description"),
+		}
+	}
+	state, err := states.Verify(query.Get("state"), sessionID)
+	if err != nil {
+		return nil, err
+	}
+	code := query.Get("code")
+	if code == "" {
+		return nil, ErrMissingCode
+	}
+	token, err := exchange(r.Context(), code)
+	if err != nil {
+		return nil, err
+	}
+	return &CallbackResult{
+		Token: token,
+		State: state,
+	}, nil
+}
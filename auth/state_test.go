@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var stateKey = bytes.Repeat([]byte("k"), MinStateKeyLength)
+
+func newTestStateManager(t *testing.T) *StateManager {
+	m, err := NewStateManager(stateKey, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// signedState will build a state signed by m with the given payload, to
+// reach the cases New never builds
+func signedState(m *StateManager, payload statePayload) string {
+	buf, _ := json.Marshal(payload)
+	encoded := base64.RawURLEncoding.EncodeToString(buf)
+	return encoded + "." + m.sign(encoded)
+}
+
+func TestNewStateManagerKey(t *testing.T) {
+	for _, key := range [][]byte{nil, {}, stateKey[:MinStateKeyLength-1]} {
+		if _, err := NewStateManager(key, 0); err == nil {
+			t.Errorf("NewStateManager with a %d bytes key gave no error", len(key))
+		}
+	}
+	m, err := NewStateManager(stateKey, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.ttl != DefaultStateTTL {
+		t.Errorf("ttl = %s, want %s", m.ttl, DefaultStateTTL)
+	}
+}
+
+func TestStateManagerVerify(t *testing.T) {
+	m := newTestStateManager(t)
+	value, err := m.New("session-1", "/dashboard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, err := m.Verify(value, "session-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.SessionID != "session-1" || state.ReturnURL != "/dashboard" || state.Nonce == "" {
+		t.Errorf("state = %+v, want session-1 and /dashboard", state)
+	}
+	if until := time.Until(state.ExpiresAt); until <= 0 || until > time.Minute {
+		t.Errorf("state expires in %s, want within a minute", until)
+	}
+}
+
+func TestStateManagerVerifyErrors(t *testing.T) {
+	m := newTestStateManager(t)
+	other, err := NewStateManager(bytes.Repeat([]byte("o"), MinStateKeyLength), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := m.New("session-1", "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(value, ".")
+	tampered := []byte(parts[0])
+	tampered[5] ^= 1
+	forged, _ := other.New("session-1", "/")
+	expired := signedState(m, statePayload{
+		State:  State{SessionID: "session-1", Nonce: "nonce"},
+		Expiry: time.Now().Add(-time.Second).Unix(),
+	})
+	emptySession := signedState(m, statePayload{
+		State:  State{Nonce: "nonce"},
+		Expiry: time.Now().Add(time.Minute).Unix(),
+	})
+
+	tests := []struct {
+		name      string
+		value     string
+		sessionID string
+		want      error
+	}{
+		{"tampered MAC", parts[0] + "." + strings.Repeat("A", len(parts[1])), "session-1", ErrInvalidState},
+		{"tampered payload", string(tampered) + "." + parts[1], "session-1", ErrInvalidState},
+		{"signed with another key", forged, "session-1", ErrInvalidState},
+		{"malformed", "state", "session-1", ErrInvalidState},
+		{"wrong session", value, "session-2", ErrInvalidState},
+		{"empty session", emptySession, "", ErrNoSessionID},
+		{"expired", expired, "session-1", ErrStateExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if state, err := m.Verify(tt.value, tt.sessionID); err != tt.want {
+				t.Errorf("Verify = %+v, %v, want %v", state, err, tt.want)
+			}
+		})
+	}
+
+	// None of the failures used the state
+	if _, err := m.Verify(value, "session-1"); err != nil {
+		t.Errorf("Verify of the valid state = %v", err)
+	}
+}
+
+func TestStateManagerNewEmptySession(t *testing.T) {
+	m := newTestStateManager(t)
+	if _, err := m.New("", "/"); err != ErrNoSessionID {
+		t.Errorf("New = %v, want ErrNoSessionID", err)
+	}
+}
+
+func TestStateManagerReplay(t *testing.T) {
+	m := newTestStateManager(t)
+	value, err := m.New("session-1", "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Verify(value, "session-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Verify(value, "session-1"); err != ErrStateUsed {
+		t.Errorf("second Verify = %v, want ErrStateUsed", err)
+	}
+}
+
+func TestHandleCallbackStateChecks(t *testing.T) {
+	provider := NewProvider(Config{ClientID: "client"})
+	m := newTestStateManager(t)
+	value, err := m.New("session-1", "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	denied := httptest.NewRequest("GET", "/callback?error=access_denied&state="+value, nil)
+	if _, err := provider.HandleCallback(denied, m, "session-1"); err == nil || !strings.Contains(err.Error(), "access_denied") {
+		t.Errorf("HandleCallback of a denied access = %v, want access_denied", err)
+	}
+	noCode := httptest.NewRequest("GET", "/callback?state="+value, nil)
+	if _, err := provider.HandleCallback(noCode, m, "session-1"); err != ErrMissingCode {
+		t.Errorf("HandleCallback without code = %v, want ErrMissingCode", err)
+	}
+	forged := httptest.NewRequest("GET", "/callback?code=code&state="+value, nil)
+	if _, err := provider.HandleCallback(forged, m, "session-2"); err != ErrInvalidState {
+		t.Errorf("HandleCallback of another session = %v, want ErrInvalidState", err)
+	}
+}
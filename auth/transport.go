@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+
+	"golang.org/x/oauth2"
+)
+
+// ContextTokenSource is an oauth2.TokenSource that can bind the token
+// refresh to a context
+type ContextTokenSource interface {
+	oauth2.TokenSource
+	TokenContext(ctx context.Context) (*oauth2.Token, error)
+}
+
+// TokenTransport is a http.RoundTripper that adds the Authorization header on
+// each request. Unlike oauth2.Transport the token is requested with the
+// context of the request, so cancellations and deadlines reach the refresh
+type TokenTransport struct {
+	Base   http.RoundTripper
+	Source ContextTokenSource
+}
+
+// RoundTrip method will authorize the request with a valid token
+func (t *TokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.Source.TokenContext(req.Context())
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	authorized := req.Clone(req.Context())
+	token.SetAuthHeader(authorized)
+	return t.Base.RoundTrip(authorized)
+}
@@ -0,0 +1,234 @@
+// Package xerosdk gives a single entry point to the Xero API, the Client type
+// keeps the http.Client, tenant and URLs used by every resource of the SDK
+package xerosdk
+
+import (
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/accounting"
+	"github.com/quickaco/xerosdk/auth"
+	"github.com/quickaco/xerosdk/connection"
+	"github.com/quickaco/xerosdk/helpers"
+)
+
+const userAgentHeader = "User-Agent"
+
+// Config keeps the information needed to build a Client, every empty URL
+// falls back to the production Xero URL
+type Config struct {
+	// HTTPClient is used for all the API calls, usually the one returned by
+	// auth.Provider.Client. If it's nil http.DefaultClient is used
+	HTTPClient *http.Client
+
+	// TenantID is sent on each request that doesn't carry its own
+	// xero-tenant-id header, leave it empty when the HTTPClient already
+	// sends it
+	TenantID uuid.UUID
+
+	// BaseURL is the base URL of the accounting API,
+	// see accounting.DefaultBaseURL
+	BaseURL string
+
+	// ConnectionsURL is the URL of the connections endpoint,
+	// see connection.DefaultURL
+	ConnectionsURL string
+
+	// AuthURL and TokenURL are the identity endpoints used by the providers
+	// built with NewProvider, see auth.DefaultAuthURL and auth.DefaultTokenURL
+	AuthURL  string
+	TokenURL string
+
+	// RevocationURL is the token revocation endpoint used by the providers
+	// built with NewProvider, see auth.DefaultRevocationURL
+	RevocationURL string
+
+	// Issuer and JWKSURL are used by the providers built with NewProvider to
+	// validate the ID tokens, see auth.DefaultIssuer and auth.DefaultJWKSURL
+	Issuer  string
+	JWKSURL string
+
+	// UserAgent is sent on each request that doesn't carry its own User-Agent
+	// header
+	UserAgent string
+
+	// Interceptors are called for each API call made with the client, in the
+	// given order, see helpers.LoggingInterceptor
+	Interceptors []helpers.Interceptor
+}
+
+// Client type gives access to all the resources of the Xero API
+type Client struct {
+	conf       Config
+	httpClient *http.Client
+}
+
+// NewClient function will build a new Client with the given criteria
+func NewClient(c Config) *Client {
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	transport := httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if len(c.Interceptors) > 0 {
+		transport = helpers.NewInterceptorTransport(transport, c.Interceptors...)
+	}
+	if c.TenantID != uuid.Nil {
+		transport = &auth.XeroTransport{T: transport, TenantID: c.TenantID}
+	}
+	if c.UserAgent != "" {
+		transport = &userAgentTransport{T: transport, UserAgent: c.UserAgent}
+	}
+	wrapped := *httpClient
+	wrapped.Transport = transport
+	return &Client{
+		conf:       c,
+		httpClient: &wrapped,
+	}
+}
+
+// WithTenant method will return a copy of the client that sends the given
+// tenantID
+func (c *Client) WithTenant(tenantID uuid.UUID) *Client {
+	conf := c.conf
+	conf.TenantID = tenantID
+	return NewClient(conf)
+}
+
+// HTTPClient method will return the http.Client used for the API calls
+func (c *Client) HTTPClient() *http.Client {
+	return c.httpClient
+}
+
+// NewProvider method will build an auth.Provider that uses the identity and
+// connections URLs of the client unless the given config overrides them
+func (c *Client) NewProvider(conf auth.Config) *auth.Provider {
+	if conf.AuthURL == "" {
+		conf.AuthURL = c.conf.AuthURL
+	}
+	if conf.TokenURL == "" {
+		conf.TokenURL = c.conf.TokenURL
+	}
+	if conf.ConnectionsURL == "" {
+		conf.ConnectionsURL = c.conf.ConnectionsURL
+	}
+	if conf.RevocationURL == "" {
+		conf.RevocationURL = c.conf.RevocationURL
+	}
+	if conf.Issuer == "" {
+		conf.Issuer = c.conf.Issuer
+	}
+	if conf.JWKSURL == "" {
+		conf.JWKSURL = c.conf.JWKSURL
+	}
+	return auth.NewProvider(conf)
+}
+
+// Accounting method will return the service for the accounting API
+func (c *Client) Accounting() *accounting.Service {
+	return accounting.NewService(c.httpClient, c.conf.BaseURL)
+}
+
+// Connections method will return the service for the connections endpoint
+func (c *Client) Connections() *connection.Service {
+	return connection.NewService(c.httpClient, c.conf.ConnectionsURL)
+}
+
+// Accounts method will return the service for the Accounts endpoint
+func (c *Client) Accounts() *accounting.AccountService {
+	return c.Accounting().Accounts()
+}
+
+// BankTransactions method will return the service for the BankTransactions
+// endpoint
+func (c *Client) BankTransactions() *accounting.BankTransactionService {
+	return c.Accounting().BankTransactions()
+}
+
+// BankTransfers method will return the service for the BankTransfers endpoint
+func (c *Client) BankTransfers() *accounting.BankTransferService {
+	return c.Accounting().BankTransfers()
+}
+
+// BatchPayments method will return the service for the BatchPayments endpoint
+func (c *Client) BatchPayments() *accounting.BatchPaymentService {
+	return c.Accounting().BatchPayments()
+}
+
+// BrandingThemes method will return the service for the BrandingThemes
+// endpoint
+func (c *Client) BrandingThemes() *accounting.BrandingThemeService {
+	return c.Accounting().BrandingThemes()
+}
+
+// Contacts method will return the service for the Contacts endpoint
+func (c *Client) Contacts() *accounting.ContactService {
+	return c.Accounting().Contacts()
+}
+
+// ContactGroups method will return the service for the ContactGroups endpoint
+func (c *Client) ContactGroups() *accounting.ContactGroupService {
+	return c.Accounting().ContactGroups()
+}
+
+// CreditNotes method will return the service for the CreditNotes endpoint
+func (c *Client) CreditNotes() *accounting.CreditNoteService {
+	return c.Accounting().CreditNotes()
+}
+
+// Currencies method will return the service for the Currencies endpoint
+func (c *Client) Currencies() *accounting.CurrencyService {
+	return c.Accounting().Currencies()
+}
+
+// Employees method will return the service for the Employees endpoint
+func (c *Client) Employees() *accounting.EmployeeService {
+	return c.Accounting().Employees()
+}
+
+// History method will return the service for the history and notes of the
+// documents
+func (c *Client) History() *accounting.HistoryService {
+	return c.Accounting().History()
+}
+
+// Invoices method will return the service for the Invoices endpoint
+func (c *Client) Invoices() *accounting.InvoiceService {
+	return c.Accounting().Invoices()
+}
+
+// InvoiceReminders method will return the service for the InvoiceReminders
+// endpoint
+func (c *Client) InvoiceReminders() *accounting.InvoiceReminderService {
+	return c.Accounting().InvoiceReminders()
+}
+
+// Items method will return the service for the Items endpoint
+func (c *Client) Items() *accounting.ItemService {
+	return c.Accounting().Items()
+}
+
+// Organisations method will return the service for the Organisations endpoint
+func (c *Client) Organisations() *accounting.OrganisationService {
+	return c.Accounting().Organisations()
+}
+
+// userAgentTransport will set the User-Agent header on the requests that
+// don't have one
+type userAgentTransport struct {
+	T         http.RoundTripper
+	UserAgent string
+}
+
+// RoundTrip method will add the User-Agent header when missing
+func (ut *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get(userAgentHeader) != "" {
+		return ut.T.RoundTrip(req)
+	}
+	r := req.Clone(req.Context())
+	r.Header.Set(userAgentHeader, ut.UserAgent)
+	return ut.T.RoundTrip(r)
+}
@@ -1,15 +1,18 @@
 package connection
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/gofrs/uuid"
 	"github.com/quickaco/xerosdk/helpers"
 )
 
 const (
-	connectionsURL = "https://api.xero.com/connections"
+	// DefaultURL is the URL of the Xero connections endpoint
+	DefaultURL = "https://api.xero.com/connections"
 )
 
 // Tenant type will keep information about the Xero tenant
@@ -19,9 +22,29 @@ type Tenant struct {
 	TenantType string    `json:"tenantType,omitempty"`
 }
 
-// GetTenants will return the value of the getting information from xero
-func GetTenants(cl *http.Client) (tenants []Tenant, err error) {
-	tenantResponseBytes, err := helpers.Find(cl, connectionsURL, nil, nil)
+// Service keeps the http.Client and the URL used to reach the Xero
+// connections endpoint
+type Service struct {
+	client *http.Client
+	url    string
+}
+
+// NewService function will build a new Service with the given http.Client,
+// if url is empty DefaultURL is used
+func NewService(cl *http.Client, url string) *Service {
+	if url == "" {
+		url = DefaultURL
+	}
+	return &Service{
+		client: cl,
+		url:    strings.TrimSuffix(url, "/"),
+	}
+}
+
+// Tenants method will return the tenants connected with the token of the
+// client
+func (s *Service) Tenants(ctx context.Context) (tenants []Tenant, err error) {
+	tenantResponseBytes, err := helpers.FindContext(withOperation(ctx, "List"), s.client, s.url, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -31,11 +54,37 @@ func GetTenants(cl *http.Client) (tenants []Tenant, err error) {
 	return tenants, nil
 }
 
-// DeleteTenant will remove the connection with the given connectionID
-func DeleteTenant(cl *http.Client, connectionID uuid.UUID) error {
-	_, err := helpers.Remove(cl, connectionsURL+"/"+connectionID.String())
+// Delete method will remove the connection with the given connectionID
+func (s *Service) Delete(ctx context.Context, connectionID uuid.UUID) error {
+	_, err := helpers.RemoveContext(withOperation(ctx, "Delete"), s.client, s.url+"/"+connectionID.String())
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// withOperation will tell the interceptors which operation the requests made
+// with the returned context belong to
+func withOperation(ctx context.Context, name string) context.Context {
+	return helpers.WithOperation(ctx, helpers.Operation{Resource: "Connections", Name: name})
+}
+
+// GetTenants will return the value of the getting information from xero
+func GetTenants(cl *http.Client) (tenants []Tenant, err error) {
+	return GetTenantsContext(context.Background(), cl)
+}
+
+// GetTenantsContext is the same as GetTenants but the given context is used for the request
+func GetTenantsContext(ctx context.Context, cl *http.Client) (tenants []Tenant, err error) {
+	return NewService(cl, DefaultURL).Tenants(ctx)
+}
+
+// DeleteTenant will remove the connection with the given connectionID
+func DeleteTenant(cl *http.Client, connectionID uuid.UUID) error {
+	return DeleteTenantContext(context.Background(), cl, connectionID)
+}
+
+// DeleteTenantContext is the same as DeleteTenant but the given context is used for the request
+func DeleteTenantContext(ctx context.Context, cl *http.Client, connectionID uuid.UUID) error {
+	return NewService(cl, DefaultURL).Delete(ctx, connectionID)
+}
@@ -0,0 +1,442 @@
+// Package decimal gives an exact fixed-point number for the amounts, rates and
+// quantities sent and received by the Xero API, so they don't pick up the
+// rounding errors of float64
+package decimal
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Places is the number of decimal places kept by a Decimal, enough for the 4
+// places of the unit amounts asked with unitdp=4 and the 6 places of the
+// currency rates
+const Places = 6
+
+const scale = 1000000
+
+var (
+	bigScale = big.NewInt(scale)
+	null     = []byte("null")
+)
+
+// smallPow10 keeps the powers of 10 up to scale
+var smallPow10 = [Places + 1]int64{1, 10, 100, 1000, 10000, 100000, scale}
+
+// currencyPlaces keeps the currencies whose minor unit isn't the cent
+var currencyPlaces = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// Decimal is a number with Places decimal places, kept as an integer number
+// of millionths with no limit on its size. The zero value is 0, a Decimal is
+// comparable with == and its zero value is sent to Xero as 0
+type Decimal struct {
+	// units is the number of millionths when it fits an int64, which covers
+	// amounts up to 9 trillion without allocating
+	units int64
+
+	// large is the number of millionths in base 10 when it doesn't fit an
+	// int64, units is 0 then, so each number has a single representation
+	large string
+}
+
+// Zero is the zero Decimal
+var Zero = Decimal{}
+
+// New function will build the Decimal value * 10^-places, e.g. New(1999, 2)
+// is 19.99. Places beyond Places are rounded half away from zero
+func New(value int64, places int) Decimal {
+	if places < 0 || places > Places {
+		return fromRat(new(big.Rat).Mul(new(big.Rat).SetInt64(value), ratPow10(-places)))
+	}
+	if units, ok := mul64(value, smallPow10[Places-places]); ok {
+		return Decimal{units: units}
+	}
+	return fromBig(new(big.Int).Mul(big.NewInt(value), pow10(Places-places)))
+}
+
+// NewFromInt function will build a Decimal from the given integer
+func NewFromInt(value int64) Decimal {
+	return New(value, 0)
+}
+
+// NewFromFloat function will build a Decimal from the shortest decimal
+// representation of the given float64, it's meant for moving code that uses
+// float64 amounts. NaN and the infinities give an error
+func NewFromFloat(value float64) (Decimal, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return Zero, fmt.Errorf("decimal: %v is not a number", value)
+	}
+	return Parse(strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// Parse function will read a Decimal from its decimal representation, such as
+// "-12.34" or "1e3". Digits beyond Places are rounded half away from zero
+func Parse(value string) (Decimal, error) {
+	value = strings.TrimSpace(value)
+	mantissa := value
+	if mantissa != "" && (mantissa[0] == '+' || mantissa[0] == '-') {
+		mantissa = mantissa[1:]
+	}
+	exponent := strings.IndexAny(mantissa, "eE")
+	if exponent >= 0 {
+		digits := strings.TrimPrefix(strings.TrimPrefix(mantissa[exponent+1:], "+"), "-")
+		if len(mantissa)-exponent-1-len(digits) > 1 || !isDigits(digits, false) {
+			return Zero, invalidNumber(value)
+		}
+		mantissa = mantissa[:exponent]
+	}
+	integer, fraction := mantissa, ""
+	if dot := strings.IndexByte(mantissa, '.'); dot >= 0 {
+		integer, fraction = mantissa[:dot], mantissa[dot+1:]
+	}
+	if (integer == "" && fraction == "") || !isDigits(integer, true) || !isDigits(fraction, true) {
+		return Zero, invalidNumber(value)
+	}
+	if exponent < 0 {
+		if d, ok := parseSmall(value[0] == '-', integer, fraction); ok {
+			return d, nil
+		}
+	}
+	r, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return Zero, invalidNumber(value)
+	}
+	return fromRat(r), nil
+}
+
+func invalidNumber(value string) error {
+	return fmt.Errorf("decimal: invalid number %q", value)
+}
+
+// isDigits will tell if s has only ASCII digits, the empty string is only
+// accepted when empty is true
+func isDigits(s string, empty bool) bool {
+	if s == "" {
+		return empty
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseSmall will read the common numbers, those that fit an int64 with at
+// most Places decimal places, without going through big.Rat
+func parseSmall(negative bool, integer string, fraction string) (Decimal, bool) {
+	if len(fraction) > Places || len(integer) > 12 {
+		return Zero, false
+	}
+	var units int64
+	for i := 0; i < len(integer); i++ {
+		units = units*10 + int64(integer[i]-'0')
+	}
+	var fractional int64
+	for i := 0; i < len(fraction); i++ {
+		fractional = fractional*10 + int64(fraction[i]-'0')
+	}
+	units = units*scale + fractional*smallPow10[Places-len(fraction)]
+	if negative {
+		units = -units
+	}
+	return Decimal{units: units}, true
+}
+
+// MustParse function is the same as Parse but it panics when the value is not
+// valid, it's meant for constants
+func MustParse(value string) Decimal {
+	d, err := Parse(value)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// Sum function will add all the given decimals
+func Sum(values ...Decimal) Decimal {
+	total := Zero
+	for _, value := range values {
+		total = total.Add(value)
+	}
+	return total
+}
+
+// CurrencyPlaces function will return the number of decimal places used by
+// the given ISO 4217 currency code, 2 for the unknown ones
+func CurrencyPlaces(currencyCode string) int {
+	if places, ok := currencyPlaces[strings.ToUpper(currencyCode)]; ok {
+		return places
+	}
+	return 2
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// ratPow10 will return 10^n, n can be negative
+func ratPow10(n int) *big.Rat {
+	if n < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), pow10(-n))
+	}
+	return new(big.Rat).SetInt(pow10(n))
+}
+
+// add64 will return a + b and false when it overflows
+func add64(a int64, b int64) (int64, bool) {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		return 0, false
+	}
+	return sum, true
+}
+
+// mul64 will return a * b and false when it overflows
+func mul64(a int64, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	product := a * b
+	if product/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return product, true
+}
+
+// roundQuo will return num / denom rounded half away from zero
+func roundQuo(num *big.Int, denom *big.Int) *big.Int {
+	q, rem := new(big.Int).QuoRem(num, denom, new(big.Int))
+	if rem.Sign() != 0 && new(big.Int).Abs(new(big.Int).Lsh(rem, 1)).Cmp(new(big.Int).Abs(denom)) >= 0 {
+		if rem.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+func fromRat(r *big.Rat) Decimal {
+	return fromBig(roundQuo(new(big.Int).Mul(r.Num(), bigScale), r.Denom()))
+}
+
+func fromBig(units *big.Int) Decimal {
+	if units.IsInt64() {
+		return Decimal{units: units.Int64()}
+	}
+	return Decimal{large: units.String()}
+}
+
+// small will tell if d is kept in an int64
+func (d Decimal) small() bool {
+	return d.large == ""
+}
+
+// big will return the number of millionths of d
+func (d Decimal) big() *big.Int {
+	if d.small() {
+		return big.NewInt(d.units)
+	}
+	units, _ := new(big.Int).SetString(d.large, 10)
+	return units
+}
+
+func (d Decimal) rat() *big.Rat {
+	return new(big.Rat).SetFrac(d.big(), bigScale)
+}
+
+// Add method will return d + other
+func (d Decimal) Add(other Decimal) Decimal {
+	if d.small() && other.small() {
+		if sum, ok := add64(d.units, other.units); ok {
+			return Decimal{units: sum}
+		}
+	}
+	return fromBig(new(big.Int).Add(d.big(), other.big()))
+}
+
+// Sub method will return d - other
+func (d Decimal) Sub(other Decimal) Decimal {
+	return d.Add(other.Neg())
+}
+
+// Mul method will return d * other rounded to Places
+func (d Decimal) Mul(other Decimal) Decimal {
+	return fromBig(roundQuo(new(big.Int).Mul(d.big(), other.big()), bigScale))
+}
+
+// Div method will return d / other rounded to Places, it panics when other
+// is zero
+func (d Decimal) Div(other Decimal) Decimal {
+	if other.IsZero() {
+		panic("decimal: division by zero")
+	}
+	return fromBig(roundQuo(new(big.Int).Mul(d.big(), bigScale), other.big()))
+}
+
+// Neg method will return -d
+func (d Decimal) Neg() Decimal {
+	if d.small() && d.units != math.MinInt64 {
+		return Decimal{units: -d.units}
+	}
+	return fromBig(new(big.Int).Neg(d.big()))
+}
+
+// Abs method will return the absolute value of d
+func (d Decimal) Abs() Decimal {
+	if d.Sign() < 0 {
+		return d.Neg()
+	}
+	return d
+}
+
+// Round method will round d to the given decimal places, half away from zero
+func (d Decimal) Round(places int) Decimal {
+	if places >= Places {
+		return d
+	}
+	if places < 0 {
+		places = 0
+	}
+	unit := smallPow10[Places-places]
+	if d.small() && d.units > math.MinInt64+unit && d.units < math.MaxInt64-unit {
+		q, rem := d.units/unit, d.units%unit
+		switch {
+		case 2*rem >= unit:
+			q++
+		case 2*rem <= -unit:
+			q--
+		}
+		return Decimal{units: q * unit}
+	}
+	bigUnit := big.NewInt(unit)
+	return fromBig(new(big.Int).Mul(roundQuo(d.big(), bigUnit), bigUnit))
+}
+
+// RoundCurrency method will round d to the decimal places of the given
+// currency, see CurrencyPlaces
+func (d Decimal) RoundCurrency(currencyCode string) Decimal {
+	return d.Round(CurrencyPlaces(currencyCode))
+}
+
+// Cmp method will return -1, 0 or +1 when d is lower than, equal to or
+// greater than other
+func (d Decimal) Cmp(other Decimal) int {
+	if d.small() && other.small() {
+		switch {
+		case d.units < other.units:
+			return -1
+		case d.units > other.units:
+			return 1
+		}
+		return 0
+	}
+	return d.big().Cmp(other.big())
+}
+
+// Equal method will tell if d and other are the same number
+func (d Decimal) Equal(other Decimal) bool {
+	return d == other
+}
+
+// Sign method will return -1, 0 or +1 depending on the sign of d
+func (d Decimal) Sign() int {
+	switch {
+	case !d.small() && d.large[0] == '-', d.units < 0:
+		return -1
+	case d.IsZero():
+		return 0
+	}
+	return 1
+}
+
+// IsZero method will tell if d is zero
+func (d Decimal) IsZero() bool {
+	return d == Zero
+}
+
+// Float64 method will return the nearest float64 to d, it's meant for the
+// code that still works with float64 amounts
+func (d Decimal) Float64() float64 {
+	f, _ := d.rat().Float64()
+	return f
+}
+
+// String method will return d without the trailing zeros, e.g. "19.9"
+func (d Decimal) String() string {
+	s := d.StringFixed(Places)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// StringFixed method will return d rounded to the given decimal places and
+// padded with zeros, e.g. "19.90" for 2 places
+func (d Decimal) StringFixed(places int) string {
+	if places > Places {
+		places = Places
+	}
+	if places < 0 {
+		places = 0
+	}
+	rounded := d.Round(places)
+	if rounded.small() && rounded.units != math.MinInt64 {
+		units, sign := rounded.units, ""
+		if units < 0 {
+			units, sign = -units, "-"
+		}
+		integer := strconv.FormatInt(units/scale, 10)
+		if places == 0 {
+			return sign + integer
+		}
+		fraction := strconv.FormatInt(units%scale+scale, 10)[1:]
+		return sign + integer + "." + fraction[:places]
+	}
+	units := rounded.big()
+	sign := ""
+	if units.Sign() < 0 {
+		sign = "-"
+		units.Neg(units)
+	}
+	integer, fraction := units.QuoRem(units, bigScale, new(big.Int))
+	if places == 0 {
+		return sign + integer.String()
+	}
+	return sign + integer.String() + "." + fmt.Sprintf("%06s", fraction.String())[:places]
+}
+
+// MarshalJSON method will send d as a JSON number, the accounting models
+// leave the zero amounts out as they did with float64
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON method will read d from a JSON number or string, null is
+// read as zero
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, null) {
+		*d = Zero
+		return nil
+	}
+	value := string(bytes.Trim(data, `"`))
+	if value == "" {
+		*d = Zero
+		return nil
+	}
+	parsed, err := Parse(value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
@@ -0,0 +1,197 @@
+package decimal
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"0", "0"},
+		{"-0", "0"},
+		{"19.99", "19.99"},
+		{"+19.990", "19.99"},
+		{"-12.34", "-12.34"},
+		{".5", "0.5"},
+		{"5.", "5"},
+		{" 7 ", "7"},
+		{"1e3", "1000"},
+		{"1.5E-2", "0.015"},
+		{"1.0000005", "1.000001"},
+		{"-1.0000005", "-1.000001"},
+		{"1.00000049", "1"},
+		{"123456789012345678901234567890.5", "123456789012345678901234567890.5"},
+	}
+	for _, tt := range tests {
+		d, err := Parse(tt.value)
+		if err != nil {
+			t.Errorf("Parse(%q) = %v", tt.value, err)
+			continue
+		}
+		if got := d.String(); got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{"", ".", "-", "+-1", "1/3", "0x10", "1e", "1e+", "1e--3", "1.2.3", "1,5", "NaN", "Inf", "1 2", "١٢"} {
+		if d, err := Parse(value); err == nil {
+			t.Errorf("Parse(%q) = %s, want an error", value, d)
+		}
+	}
+}
+
+func TestComparable(t *testing.T) {
+	large := MustParse("98765432109876543210")
+	if MustParse("1.50") != MustParse("1.5") || MustParse("-0") != Zero {
+		t.Error("the same numbers are not ==")
+	}
+	if large.Sub(large) != Zero || large.Add(NewFromInt(1)).Sub(NewFromInt(1)) != large {
+		t.Error("the large numbers are not ==")
+	}
+	if small := large.Sub(MustParse("98765432109876543209")); small != NewFromInt(1) {
+		t.Errorf("large - large = %s, want 1 kept as a small number", small)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	max := MustParse("9223372036854.775807")
+	tests := []struct {
+		name string
+		got  Decimal
+		want string
+	}{
+		{"add", MustParse("0.1").Add(MustParse("0.2")), "0.3"},
+		{"add overflow", max.Add(MustParse("0.000001")), "9223372036854.775808"},
+		{"sub", MustParse("10").Sub(MustParse("0.01")), "9.99"},
+		{"sub underflow", max.Neg().Sub(MustParse("0.000002")), "-9223372036854.775809"},
+		{"mul", MustParse("19.99").Mul(MustParse("3")), "59.97"},
+		{"mul rounded", MustParse("0.000001").Mul(MustParse("0.5")), "0.000001"},
+		{"div", MustParse("10").Div(MustParse("3")), "3.333333"},
+		{"div rounded", MustParse("-20").Div(MustParse("3")), "-6.666667"},
+		{"sum", Sum(MustParse("1.1"), MustParse("2.2"), MustParse("-0.3")), "3"},
+		{"abs", MustParse("-4.5").Abs(), "4.5"},
+		{"new", New(1999, 2), "19.99"},
+		{"new rounded", New(15, 7), "0.000002"},
+		{"new negative places", New(15, -2), "1500"},
+	}
+	for _, tt := range tests {
+		if got := tt.got.String(); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+	if MustParse("1.5").Cmp(MustParse("1.25")) != 1 || max.Add(max).Cmp(max) != 1 || MustParse("-1").Sign() != -1 || max.Add(max).Neg().Sign() != -1 {
+		t.Error("Cmp or Sign are wrong")
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		value  string
+		places int
+		want   string
+	}{
+		{"2.345", 2, "2.35"},
+		{"-2.345", 2, "-2.35"},
+		{"2.344", 2, "2.34"},
+		{"0.5", 0, "1"},
+		{"-0.5", 0, "-1"},
+		{"1.4999", 0, "1"},
+		{"2.5", -1, "3"},
+		{"99999999999999999999.5", 0, "100000000000000000000"},
+	}
+	for _, tt := range tests {
+		if got := MustParse(tt.value).Round(tt.places).String(); got != tt.want {
+			t.Errorf("Round(%s, %d) = %s, want %s", tt.value, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestStringFixed(t *testing.T) {
+	tests := []struct {
+		value  string
+		places int
+		want   string
+	}{
+		{"19.9", 2, "19.90"},
+		{"-0.005", 2, "-0.01"},
+		{"0.004", 2, "0.00"},
+		{"7", 0, "7"},
+		{"1.5", 8, "1.500000"},
+		{"-123456789012345678901234567890.125", 2, "-123456789012345678901234567890.13"},
+	}
+	for _, tt := range tests {
+		if got := MustParse(tt.value).StringFixed(tt.places); got != tt.want {
+			t.Errorf("StringFixed(%s, %d) = %s, want %s", tt.value, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestRoundCurrency(t *testing.T) {
+	tests := []struct {
+		currency string
+		want     string
+	}{
+		{"NZD", "1234.57"},
+		{"jpy", "1235"},
+		{"KWD", "1234.568"},
+		{"XXX", "1234.57"},
+	}
+	for _, tt := range tests {
+		if got := MustParse("1234.5675").RoundCurrency(tt.currency).String(); got != tt.want {
+			t.Errorf("RoundCurrency(%s) = %s, want %s", tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	type line struct {
+		Amount Decimal
+	}
+	for _, value := range []string{"0", "19.99", "-0.000001", "123456789012345678901234567890.5"} {
+		buf, err := json.Marshal(line{MustParse(value)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `{"Amount":` + value + `}`; string(buf) != want {
+			t.Errorf("Marshal = %s, want %s", buf, want)
+		}
+		var decoded line
+		if err := json.Unmarshal(buf, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Amount != MustParse(value) {
+			t.Errorf("round trip of %s = %s", value, decoded.Amount)
+		}
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"Amount":null}`, "0"},
+		{`{"Amount":"12.50"}`, "12.5"},
+		{`{"Amount":""}`, "0"},
+		{`{"Amount":1e2}`, "100"},
+		{`{}`, "0"},
+	}
+	for _, tt := range tests {
+		decoded := line{Amount: NewFromInt(5)}
+		if tt.input == `{}` {
+			decoded = line{}
+		}
+		if err := json.Unmarshal([]byte(tt.input), &decoded); err != nil {
+			t.Errorf("Unmarshal(%s) = %v", tt.input, err)
+			continue
+		}
+		if got := decoded.Amount.String(); got != tt.want {
+			t.Errorf("Unmarshal(%s) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+	var decoded line
+	if err := json.Unmarshal([]byte(`{"Amount":"1/3"}`), &decoded); err == nil {
+		t.Errorf("Unmarshal of a fraction = %s, want an error", decoded.Amount)
+	}
+}
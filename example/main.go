@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"encoding/json"
 	"flag"
 	"html/template"
@@ -21,9 +22,12 @@ import (
 	"github.com/joho/godotenv"
 )
 
+const sessionCookie = "xero_session"
+
 var (
-	c    *auth.Provider
-	repo auth.Repository
+	c      *auth.Provider
+	repo   auth.Repository
+	states *auth.StateManager
 )
 
 func init() {
@@ -38,6 +42,20 @@ func init() {
 	}
 	c = auth.NewProvider(config)
 	repo = NewRepository()
+
+	key := []byte(os.Getenv("STATE_KEY"))
+	if len(key) == 0 {
+		log.Println("No STATE_KEY found, using a random one")
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			log.Fatal(err)
+		}
+	}
+	var err error
+	states, err = auth.NewStateManager(key, 10*time.Minute)
+	if err != nil {
+		log.Fatal(err)
+	}
 }
 
 func main() {
@@ -54,6 +72,7 @@ func main() {
 	r.HandleFunc("/contacts/create", XeroContactsCreateHandler)
 	r.HandleFunc("/invoices", XeroInvoicesHandler)
 	r.HandleFunc("/refresh", XeroRefreshTokenHandler)
+	r.HandleFunc("/disconnect", XeroDisconnectHandler)
 	r.HandleFunc("/organisations", XeroOrganisationsHandler)
 	r.HandleFunc("/accounts", XeroAccountsHandler)
 	r.HandleFunc("/bankTransactions", XeroBankTransactionsHandler)
@@ -118,28 +137,55 @@ func HomeHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // StartXeroAuthHandler is the handler that will start the process of Auth with
-// the Xero platform
+// the Xero platform, the state is bound to the browser session so the callback
+// can't be forged
 func StartXeroAuthHandler(w http.ResponseWriter, r *http.Request) {
-	http.Redirect(w, r, c.GetAuthURL("uniq_state"), http.StatusFound)
+	sessionID := browserSession(w, r)
+	state, err := states.New(sessionID, "/")
+	if err != nil {
+		log.Panic(err)
+	}
+	http.Redirect(w, r, c.GetAuthURL(state), http.StatusFound)
 }
 
 // XeroAuthCallbackHandler is the handler in where we are going to receive a
 // successful callback with a code that can we use to get our user token
 func XeroAuthCallbackHandler(w http.ResponseWriter, r *http.Request) {
-	token, err := c.GetTokenFromCode(r.FormValue("code"))
+	result, err := c.HandleCallback(r, states, browserSession(w, r))
 	if err != nil {
-		log.Panic(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-	repo.CreateSession(uuid.Nil, token)
+	repo.CreateSession(uuid.Nil, result.Token)
 	t, _ := template.New("connected").Parse(connectedTemplate)
-	t.Execute(w, token)
+	t.Execute(w, result.Token)
+}
+
+// browserSession will return the id of the session of the browser, starting a
+// new one when there is none
+func browserSession(w http.ResponseWriter, r *http.Request) string {
+	if cookie, err := r.Cookie(sessionCookie); err == nil && cookie.Value != "" {
+		return cookie.Value
+	}
+	id, err := uuid.NewV4()
+	if err != nil {
+		log.Panic(err)
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    id.String(),
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return id.String()
 }
 
 // XeroConnectionsHandler is the handler that will show all the granted access
 // tenants
 func XeroConnectionsHandler(w http.ResponseWriter, r *http.Request) {
 	se, _ := repo.GetSession(uuid.Nil)
-	tenants, err := connection.GetTenants(c.Client(&auth.Session{
+	tenants, err := connection.GetTenantsContext(r.Context(), c.Client(&auth.Session{
 		Token:    se,
 		UserID:   uuid.Nil,
 		TenantID: uuid.Nil,
@@ -155,7 +201,7 @@ func XeroConnectionsHandler(w http.ResponseWriter, r *http.Request) {
 // an saved it as a current one
 func XeroRefreshTokenHandler(w http.ResponseWriter, r *http.Request) {
 	se, _ := repo.GetSession(uuid.Nil)
-	newToken, err := c.Refresh(se)
+	newToken, err := c.RefreshContext(r.Context(), se)
 	if err != nil {
 		log.Panic(err)
 	}
@@ -163,6 +209,17 @@ func XeroRefreshTokenHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/", http.StatusFound)
 }
 
+// XeroDisconnectHandler is the handler that will remove all the connections,
+// revoke the token and forget the session
+func XeroDisconnectHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := c.Disconnect(r.Context(), repo, uuid.Nil)
+	if err != nil {
+		log.Println(err)
+	}
+	log.Printf("disconnected %d tenants, revoked: %t, session deleted: %t", len(report.Tenants), report.Revoked, report.SessionDeleted)
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
 //XeroContactsHandler is the handler in where we will show all the existing contacts
 // with all the tenants connected
 func XeroContactsHandler(w http.ResponseWriter, r *http.Request) {
@@ -175,12 +232,12 @@ func XeroContactsHandler(w http.ResponseWriter, r *http.Request) {
 	})
 	contacts := []accounting.Contact{}
 
-	tenants, err := connection.GetTenants(cl)
+	tenants, err := connection.GetTenantsContext(r.Context(), cl)
 	if err != nil {
 		log.Panic(err)
 	}
 	for _, tenant := range tenants {
-		c, err := accounting.FindContacts(c.Client(&auth.Session{
+		c, err := accounting.FindContactsContext(r.Context(), c.Client(&auth.Session{
 			Token:    se,
 			UserID:   uuid.Nil,
 			TenantID: tenant.TenantID,
@@ -214,7 +271,7 @@ func XeroContactsCreateHandler(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
-	tenants, err := connection.GetTenants(c.Client(&auth.Session{
+	tenants, err := connection.GetTenantsContext(r.Context(), c.Client(&auth.Session{
 		Token:  se,
 		UserID: uuid.Nil,
 		Repo:   repo,
@@ -224,7 +281,7 @@ func XeroContactsCreateHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	// We asume we have at least one tenant connected
 	// TODO improve that to get this information from a form
-	_, err = contacts.Create(c.Client(&auth.Session{
+	_, err = contacts.CreateContext(r.Context(), c.Client(&auth.Session{
 		Token:    se,
 		UserID:   uuid.Nil,
 		TenantID: tenants[0].TenantID,
@@ -242,7 +299,7 @@ func XeroInvoicesHandler(w http.ResponseWriter, r *http.Request) {
 	invoices := []accounting.Invoice{}
 	se, _ := repo.GetSession(uuid.Nil)
 
-	tenants, err := connection.GetTenants(c.Client(&auth.Session{
+	tenants, err := connection.GetTenantsContext(r.Context(), c.Client(&auth.Session{
 		Token:  se,
 		UserID: uuid.Nil,
 		Repo:   repo,
@@ -251,7 +308,7 @@ func XeroInvoicesHandler(w http.ResponseWriter, r *http.Request) {
 		log.Panic(err)
 	}
 	for _, tenant := range tenants {
-		i, err := accounting.FindInvoices(c.Client(&auth.Session{
+		i, err := accounting.FindInvoicesContext(r.Context(), c.Client(&auth.Session{
 			Token:    se,
 			UserID:   uuid.Nil,
 			TenantID: tenant.TenantID,
@@ -276,7 +333,7 @@ func XeroOrganisationsHandler(w http.ResponseWriter, r *http.Request) {
 	organisations := []accounting.Organisation{}
 	se, _ := repo.GetSession(uuid.Nil)
 
-	tenants, err := connection.GetTenants(c.Client(&auth.Session{
+	tenants, err := connection.GetTenantsContext(r.Context(), c.Client(&auth.Session{
 		Token:  se,
 		UserID: uuid.Nil,
 		Repo:   repo,
@@ -285,7 +342,7 @@ func XeroOrganisationsHandler(w http.ResponseWriter, r *http.Request) {
 		log.Panic(err)
 	}
 	for _, tenant := range tenants {
-		orgs, err := accounting.FindOrganisations(c.Client(&auth.Session{
+		orgs, err := accounting.FindOrganisationsContext(r.Context(), c.Client(&auth.Session{
 			Token:    se,
 			UserID:   uuid.Nil,
 			TenantID: tenant.TenantID,
@@ -310,7 +367,7 @@ func XeroAccountsHandler(w http.ResponseWriter, r *http.Request) {
 	accounts := []accounting.Account{}
 	se, _ := repo.GetSession(uuid.Nil)
 
-	tenants, err := connection.GetTenants(c.Client(&auth.Session{
+	tenants, err := connection.GetTenantsContext(r.Context(), c.Client(&auth.Session{
 		Token:  se,
 		UserID: uuid.Nil,
 		Repo:   repo,
@@ -319,7 +376,7 @@ func XeroAccountsHandler(w http.ResponseWriter, r *http.Request) {
 		log.Panic(err)
 	}
 	for _, tenant := range tenants {
-		accs, err := accounting.FindAccounts(c.Client(&auth.Session{
+		accs, err := accounting.FindAccountsContext(r.Context(), c.Client(&auth.Session{
 			Token:    se,
 			UserID:   uuid.Nil,
 			TenantID: tenant.TenantID,
@@ -344,7 +401,7 @@ func XeroBankTransactionsHandler(w http.ResponseWriter, r *http.Request) {
 	bankTransactions := []accounting.BankTransaction{}
 	se, _ := repo.GetSession(uuid.Nil)
 
-	tenants, err := connection.GetTenants(c.Client(&auth.Session{
+	tenants, err := connection.GetTenantsContext(r.Context(), c.Client(&auth.Session{
 		Token:  se,
 		UserID: uuid.Nil,
 		Repo:   repo,
@@ -353,7 +410,7 @@ func XeroBankTransactionsHandler(w http.ResponseWriter, r *http.Request) {
 		log.Panic(err)
 	}
 	for _, tenant := range tenants {
-		bankTr, err := accounting.FindBankTransactions(c.Client(&auth.Session{
+		bankTr, err := accounting.FindBankTransactionsContext(r.Context(), c.Client(&auth.Session{
 			Token:    se,
 			UserID:   uuid.Nil,
 			TenantID: tenant.TenantID,
@@ -378,7 +435,7 @@ func XeroBankTransfersHandler(w http.ResponseWriter, r *http.Request) {
 	bankTransfers := []accounting.BankTransfer{}
 	se, _ := repo.GetSession(uuid.Nil)
 
-	tenants, err := connection.GetTenants(c.Client(&auth.Session{
+	tenants, err := connection.GetTenantsContext(r.Context(), c.Client(&auth.Session{
 		Token:  se,
 		UserID: uuid.Nil,
 		Repo:   repo,
@@ -387,7 +444,7 @@ func XeroBankTransfersHandler(w http.ResponseWriter, r *http.Request) {
 		log.Panic(err)
 	}
 	for _, tenant := range tenants {
-		bankTrns, err := accounting.FindBankTransfers(c.Client(&auth.Session{
+		bankTrns, err := accounting.FindBankTransfersContext(r.Context(), c.Client(&auth.Session{
 			Token:    se,
 			UserID:   uuid.Nil,
 			TenantID: tenant.TenantID,
@@ -412,7 +469,7 @@ func XeroBrandingThemeHandler(w http.ResponseWriter, r *http.Request) {
 	brandingThemes := []accounting.BrandingTheme{}
 	se, _ := repo.GetSession(uuid.Nil)
 
-	tenants, err := connection.GetTenants(c.Client(&auth.Session{
+	tenants, err := connection.GetTenantsContext(r.Context(), c.Client(&auth.Session{
 		Token:  se,
 		UserID: uuid.Nil,
 		Repo:   repo,
@@ -421,7 +478,7 @@ func XeroBrandingThemeHandler(w http.ResponseWriter, r *http.Request) {
 		log.Panic(err)
 	}
 	for _, tenant := range tenants {
-		themes, err := accounting.FindBrandingThemes(c.Client(&auth.Session{
+		themes, err := accounting.FindBrandingThemesContext(r.Context(), c.Client(&auth.Session{
 			Token:    se,
 			UserID:   uuid.Nil,
 			TenantID: tenant.TenantID,
@@ -446,7 +503,7 @@ func XeroContactGroupsHandler(w http.ResponseWriter, r *http.Request) {
 	contactGroups := []accounting.ContactGroup{}
 	se, _ := repo.GetSession(uuid.Nil)
 
-	tenants, err := connection.GetTenants(c.Client(&auth.Session{
+	tenants, err := connection.GetTenantsContext(r.Context(), c.Client(&auth.Session{
 		Token:  se,
 		UserID: uuid.Nil,
 		Repo:   repo,
@@ -455,7 +512,7 @@ func XeroContactGroupsHandler(w http.ResponseWriter, r *http.Request) {
 		log.Panic(err)
 	}
 	for _, tenant := range tenants {
-		groups, err := accounting.FindContactGroups(c.Client(&auth.Session{
+		groups, err := accounting.FindContactGroupsContext(r.Context(), c.Client(&auth.Session{
 			Token:    se,
 			UserID:   uuid.Nil,
 			TenantID: tenant.TenantID,
@@ -480,7 +537,7 @@ func XeroCreditNotesHandler(w http.ResponseWriter, r *http.Request) {
 	creditNotes := []accounting.CreditNote{}
 	se, _ := repo.GetSession(uuid.Nil)
 
-	tenants, err := connection.GetTenants(c.Client(&auth.Session{
+	tenants, err := connection.GetTenantsContext(r.Context(), c.Client(&auth.Session{
 		Token:  se,
 		UserID: uuid.Nil,
 		Repo:   repo,
@@ -489,7 +546,7 @@ func XeroCreditNotesHandler(w http.ResponseWriter, r *http.Request) {
 		log.Panic(err)
 	}
 	for _, tenant := range tenants {
-		notes, err := accounting.FindCreditNotes(c.Client(&auth.Session{
+		notes, err := accounting.FindCreditNotesContext(r.Context(), c.Client(&auth.Session{
 			Token:    se,
 			UserID:   uuid.Nil,
 			TenantID: tenant.TenantID,
@@ -514,7 +571,7 @@ func XeroCurrencyHandler(w http.ResponseWriter, r *http.Request) {
 	currencies := []accounting.Currency{}
 	se, _ := repo.GetSession(uuid.Nil)
 
-	tenants, err := connection.GetTenants(c.Client(&auth.Session{
+	tenants, err := connection.GetTenantsContext(r.Context(), c.Client(&auth.Session{
 		Token:  se,
 		UserID: uuid.Nil,
 		Repo:   repo,
@@ -523,7 +580,7 @@ func XeroCurrencyHandler(w http.ResponseWriter, r *http.Request) {
 		log.Panic(err)
 	}
 	for _, tenant := range tenants {
-		c, err := accounting.FindCurrencies(c.Client(&auth.Session{
+		c, err := accounting.FindCurrenciesContext(r.Context(), c.Client(&auth.Session{
 			Token:    se,
 			UserID:   uuid.Nil,
 			TenantID: tenant.TenantID,
@@ -548,7 +605,7 @@ func XeroEmployeesHandler(w http.ResponseWriter, r *http.Request) {
 	employees := []accounting.Employee{}
 	se, _ := repo.GetSession(uuid.Nil)
 
-	tenants, err := connection.GetTenants(c.Client(&auth.Session{
+	tenants, err := connection.GetTenantsContext(r.Context(), c.Client(&auth.Session{
 		Token:  se,
 		UserID: uuid.Nil,
 		Repo:   repo,
@@ -557,7 +614,7 @@ func XeroEmployeesHandler(w http.ResponseWriter, r *http.Request) {
 		log.Panic(err)
 	}
 	for _, tenant := range tenants {
-		e, err := accounting.FindEmployees(c.Client(&auth.Session{
+		e, err := accounting.FindEmployeesContext(r.Context(), c.Client(&auth.Session{
 			Token:    se,
 			UserID:   uuid.Nil,
 			TenantID: tenant.TenantID,
@@ -582,7 +639,7 @@ func XeroInvoiceRemindersHandler(w http.ResponseWriter, r *http.Request) {
 	reminders := []accounting.InvoiceReminder{}
 	se, _ := repo.GetSession(uuid.Nil)
 
-	tenants, err := connection.GetTenants(c.Client(&auth.Session{
+	tenants, err := connection.GetTenantsContext(r.Context(), c.Client(&auth.Session{
 		Token:  se,
 		UserID: uuid.Nil,
 		Repo:   repo,
@@ -591,7 +648,7 @@ func XeroInvoiceRemindersHandler(w http.ResponseWriter, r *http.Request) {
 		log.Panic(err)
 	}
 	for _, tenant := range tenants {
-		rem, err := accounting.FindInvoiceReminders(c.Client(&auth.Session{
+		rem, err := accounting.FindInvoiceRemindersContext(r.Context(), c.Client(&auth.Session{
 			Token:    se,
 			UserID:   uuid.Nil,
 			TenantID: tenant.TenantID,
@@ -616,7 +673,7 @@ func XeroInvoiceItemsHandler(w http.ResponseWriter, r *http.Request) {
 	items := []accounting.Item{}
 	se, _ := repo.GetSession(uuid.Nil)
 
-	tenants, err := connection.GetTenants(c.Client(&auth.Session{
+	tenants, err := connection.GetTenantsContext(r.Context(), c.Client(&auth.Session{
 		Token:  se,
 		UserID: uuid.Nil,
 		Repo:   repo,
@@ -625,7 +682,7 @@ func XeroInvoiceItemsHandler(w http.ResponseWriter, r *http.Request) {
 		log.Panic(err)
 	}
 	for _, tenant := range tenants {
-		its, err := accounting.FindItems(c.Client(&auth.Session{
+		its, err := accounting.FindItemsContext(r.Context(), c.Client(&auth.Session{
 			Token:    se,
 			UserID:   uuid.Nil,
 			TenantID: tenant.TenantID,
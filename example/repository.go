@@ -30,3 +30,8 @@ func (r *repository) UpdateSession(userID uuid.UUID, t *oauth2.Token) error {
 func (r *repository) GetSession(userID uuid.UUID) (*oauth2.Token, error) {
 	return r.sessions[userID], nil
 }
+
+func (r *repository) DeleteSession(userID uuid.UUID) error {
+	delete(r.sessions, userID)
+	return nil
+}
@@ -24,7 +24,8 @@ var connectedTemplate = `<p>AccessToken: {{.AccessToken}}</p>
 <p><a href="/employees"/>Employees</p>
 <p><a href="/invoiceReminders"/>InvoiceReminders</p>
 <p><a href="/invoiceItems"/>InvoiceItems</p>
-<p><a href="/refresh"/>Refresh</p>`
+<p><a href="/refresh"/>Refresh</p>
+<p><a href="/disconnect"/>Disconnect</p>`
 
 var contactsTemplate = `
 {{range .Contacts}}
@@ -0,0 +1,289 @@
+package helpers
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	ifModifiedSinceHeader = "If-Modified-Since"
+
+	// ifModifiedSinceLayout is the UTC timestamp without zone documented by
+	// Xero for If-Modified-Since
+	ifModifiedSinceLayout = "2006-01-02T15:04:05"
+)
+
+// DefaultCacheResources are the resources cached when CacheOptions doesn't
+// name any, reference data that hardly ever changes
+var DefaultCacheResources = []string{
+	"Accounts",
+	"BrandingThemes",
+	"Currencies",
+	"Organisations",
+	"TrackingCategories",
+}
+
+// CacheEntry is a response kept by a CacheStore. Checked is the time the
+// entry was last known to be up to date, it's sent as If-Modified-Since when
+// the entry expires
+type CacheEntry struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Checked    time.Time
+}
+
+// CacheStore keeps the cached responses, keyed by tenant and URL. The
+// implementations must be safe for concurrent use
+type CacheStore interface {
+	Get(key string) (CacheEntry, bool)
+	Set(key string, entry CacheEntry)
+	// DeletePrefix removes all the entries whose key starts with prefix
+	DeletePrefix(prefix string)
+}
+
+// MemoryCacheStore is a CacheStore that keeps the entries in memory
+type MemoryCacheStore struct {
+	mu      sync.RWMutex
+	entries map[string]CacheEntry
+}
+
+// NewMemoryCacheStore function will build a new empty MemoryCacheStore
+func NewMemoryCacheStore() *MemoryCacheStore {
+	return &MemoryCacheStore{
+		entries: make(map[string]CacheEntry),
+	}
+}
+
+// Get method will return the entry stored for the given key
+func (s *MemoryCacheStore) Get(key string) (CacheEntry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entry, ok := s.entries[key]
+	return entry, ok
+}
+
+// Set method will store the entry for the given key
+func (s *MemoryCacheStore) Set(key string, entry CacheEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = entry
+}
+
+// DeletePrefix method will remove the entries whose key starts with prefix
+func (s *MemoryCacheStore) DeletePrefix(prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// CacheOptions keeps the criteria used to build a Cache
+type CacheOptions struct {
+	// Store keeps the responses, if it's nil a MemoryCacheStore is used
+	Store CacheStore
+
+	// TTL is how long a response is served without asking Xero, once it
+	// expires the next request is sent with If-Modified-Since. When the
+	// resource changed that costs a second request, see CacheTransport
+	TTL time.Duration
+
+	// Resources are the resources cached, e.g. "Accounts". If it's empty
+	// DefaultCacheResources is used
+	Resources []string
+}
+
+// Cache keeps the GET responses of the reference data of each tenant, it's
+// used by CacheTransport
+type Cache struct {
+	store     CacheStore
+	ttl       time.Duration
+	resources map[string]bool
+}
+
+// NewCache function will build a new Cache with the given options
+func NewCache(opts CacheOptions) *Cache {
+	store := opts.Store
+	if store == nil {
+		store = NewMemoryCacheStore()
+	}
+	resources := opts.Resources
+	if len(resources) == 0 {
+		resources = DefaultCacheResources
+	}
+	c := &Cache{
+		store:     store,
+		ttl:       opts.TTL,
+		resources: make(map[string]bool, len(resources)),
+	}
+	for _, resource := range resources {
+		c.resources[resource] = true
+	}
+	return c
+}
+
+// Invalidate method will remove the cached responses of the given tenant for
+// the resource the URL belongs to, e.g. invalidating
+// https://api.xero.com/api.xro/2.0/Accounts/{id} removes the account and the
+// account lists
+func (c *Cache) Invalidate(tenantID string, resourceURL string) {
+	prefix := tenantID + "|" + resourcePath(resourceURL)
+	c.store.DeletePrefix(prefix + "|")
+	c.store.DeletePrefix(prefix + "/")
+}
+
+// InvalidateTenant method will remove all the cached responses of the given
+// tenant
+func (c *Cache) InvalidateTenant(tenantID string) {
+	c.store.DeletePrefix(tenantID + "|")
+}
+
+// cacheable will tell if the given URL belongs to a cached resource
+func (c *Cache) cacheable(req *http.Request) bool {
+	for _, segment := range strings.Split(req.URL.Path, "/") {
+		if c.resources[segment] {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheKey will build the key of a request, the query is kept apart from the
+// path so a resource can be invalidated without touching its siblings
+func cacheKey(req *http.Request) string {
+	u := *req.URL
+	u.RawQuery = ""
+	u.Fragment = ""
+	return req.Header.Get(xeroTenantIDHeader) + "|" + u.String() + "|" + req.URL.RawQuery
+}
+
+// resourcePath will strip the query and a trailing identifier from the URL,
+// leaving the collection it belongs to
+func resourcePath(rawURL string) string {
+	if i := strings.IndexAny(rawURL, "?#"); i >= 0 {
+		rawURL = rawURL[:i]
+	}
+	rawURL = strings.TrimSuffix(rawURL, "/")
+	if i := strings.LastIndex(rawURL, "/"); i >= 0 {
+		if _, err := uuid.FromString(rawURL[i+1:]); err == nil {
+			rawURL = rawURL[:i]
+		}
+	}
+	return rawURL
+}
+
+// CacheTransport is a http.RoundTripper that serves the GET requests of the
+// cached resources from a Cache. Expired entries are checked with
+// If-Modified-Since and kept when Xero answers 304 Not Modified. When Xero
+// answers 200 the resource changed, but the body only has the elements that
+// changed since the entry was checked, so the full response is asked again:
+// a changed resource costs two calls against the rate limits, an unchanged
+// one costs one. Requests that already carry If-Modified-Since skip the
+// cache, and successful writes invalidate the resource they were sent to
+type CacheTransport struct {
+	T     http.RoundTripper
+	Cache *Cache
+}
+
+// NewCacheTransport will build a new CacheTransport on top of the given
+// transport, if it's nil http.DefaultTransport is used
+func NewCacheTransport(t http.RoundTripper, cache *Cache) *CacheTransport {
+	if t == nil {
+		t = http.DefaultTransport
+	}
+	return &CacheTransport{
+		T:     t,
+		Cache: cache,
+	}
+}
+
+// RoundTrip method will serve the request from the cache when possible
+func (ct *CacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		response, err := ct.T.RoundTrip(req)
+		if err == nil && response.StatusCode < http.StatusBadRequest {
+			ct.Cache.Invalidate(req.Header.Get(xeroTenantIDHeader), req.URL.String())
+		}
+		return response, err
+	}
+	if req.Header.Get(ifModifiedSinceHeader) != "" || !ct.Cache.cacheable(req) {
+		return ct.T.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	entry, ok := ct.Cache.store.Get(key)
+	if !ok {
+		return ct.fetch(req, key)
+	}
+	if time.Since(entry.Checked) < ct.Cache.ttl {
+		return entry.response(req), nil
+	}
+
+	checked := time.Now()
+	r := req.Clone(req.Context())
+	r.Header.Set(ifModifiedSinceHeader, entry.Checked.UTC().Format(ifModifiedSinceLayout))
+	response, err := ct.T.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode == http.StatusNotModified {
+		response.Body.Close()
+		entry.Checked = checked
+		ct.Cache.store.Set(key, entry)
+		return entry.response(req), nil
+	}
+	if response.StatusCode >= http.StatusBadRequest {
+		return response, nil
+	}
+	// Xero answers If-Modified-Since with only the elements that changed,
+	// storing it would drop the others, so the full response is asked again
+	response.Body.Close()
+	return ct.fetch(req, key)
+}
+
+// fetch will send the request and store its response when it succeeds
+func (ct *CacheTransport) fetch(req *http.Request, key string) (*http.Response, error) {
+	checked := time.Now()
+	response, err := ct.T.RoundTrip(req)
+	if err != nil || response.StatusCode != http.StatusOK {
+		return response, err
+	}
+	body, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	ct.Cache.store.Set(key, CacheEntry{
+		StatusCode: response.StatusCode,
+		Header:     response.Header.Clone(),
+		Body:       body,
+		Checked:    checked,
+	})
+	response.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return response, nil
+}
+
+// response will build a response for the given request from the entry
+func (e CacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
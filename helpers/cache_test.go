@@ -0,0 +1,158 @@
+package helpers
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// accountsServer answers the accounts of each tenant with their version, it
+// answers 304 to If-Modified-Since when modified is false and keeps the
+// requests it got
+type accountsServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []*http.Request
+	version  map[string]string
+	modified bool
+}
+
+func newAccountsServer() *accountsServer {
+	s := &accountsServer{version: map[string]string{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests = append(s.requests, r)
+		tenantID := r.Header.Get(xeroTenantIDHeader)
+		if r.Method != http.MethodGet {
+			s.version[tenantID] += "+"
+			return
+		}
+		if r.Header.Get(ifModifiedSinceHeader) != "" {
+			if !s.modified {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Write([]byte("changed " + tenantID))
+			return
+		}
+		w.Write([]byte("accounts " + tenantID + s.version[tenantID]))
+	}))
+	return s
+}
+
+func (s *accountsServer) get(t *testing.T, cl *http.Client, tenantID string, path string) string {
+	req, err := http.NewRequest(http.MethodGet, s.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(xeroTenantIDHeader, tenantID)
+	resp, err := cl.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestCacheTransportTTL(t *testing.T) {
+	server := newAccountsServer()
+	defer server.Close()
+	cl := &http.Client{Transport: NewCacheTransport(nil, NewCache(CacheOptions{TTL: time.Hour}))}
+
+	for i := 0; i < 3; i++ {
+		if body := server.get(t, cl, "tenant-1", "/api.xro/2.0/Accounts"); body != "accounts tenant-1" {
+			t.Errorf("body = %q", body)
+		}
+	}
+	server.get(t, cl, "tenant-1", "/api.xro/2.0/Invoices")
+	server.get(t, cl, "tenant-1", "/api.xro/2.0/Invoices")
+	if len(server.requests) != 3 {
+		t.Errorf("requests = %d, want 1 for the accounts and 2 for the invoices", len(server.requests))
+	}
+}
+
+func TestCacheTransportRevalidation(t *testing.T) {
+	server := newAccountsServer()
+	defer server.Close()
+	cl := &http.Client{Transport: NewCacheTransport(nil, NewCache(CacheOptions{}))}
+
+	server.get(t, cl, "tenant-1", "/api.xro/2.0/Accounts")
+	if body := server.get(t, cl, "tenant-1", "/api.xro/2.0/Accounts"); body != "accounts tenant-1" {
+		t.Errorf("body after a 304 = %q, want the cached one", body)
+	}
+	if len(server.requests) != 2 {
+		t.Fatalf("requests = %d, want 2", len(server.requests))
+	}
+	since := server.requests[1].Header.Get(ifModifiedSinceHeader)
+	if _, err := time.Parse(ifModifiedSinceLayout, since); err != nil {
+		t.Errorf("If-Modified-Since = %q: %v", since, err)
+	}
+
+	// A 200 only has the changed elements, the full response is asked again
+	server.modified = true
+	server.version["tenant-1"] = " v2"
+	if body := server.get(t, cl, "tenant-1", "/api.xro/2.0/Accounts"); body != "accounts tenant-1 v2" {
+		t.Errorf("body after a change = %q, want the full response", body)
+	}
+	if len(server.requests) != 4 || server.requests[3].Header.Get(ifModifiedSinceHeader) != "" {
+		t.Errorf("requests = %d, want the conditional one and the full one", len(server.requests))
+	}
+}
+
+func TestCacheTransportInvalidation(t *testing.T) {
+	server := newAccountsServer()
+	defer server.Close()
+	cl := &http.Client{Transport: NewCacheTransport(nil, NewCache(CacheOptions{TTL: time.Hour}))}
+
+	server.get(t, cl, "tenant-1", "/api.xro/2.0/Accounts")
+	server.get(t, cl, "tenant-1", "/api.xro/2.0/Accounts?where=Type%3D%3D%22BANK%22")
+	server.get(t, cl, "tenant-2", "/api.xro/2.0/Accounts")
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/api.xro/2.0/Accounts/6f7b3f4e-4b5a-4a8e-9a52-1c8f0e0d5b3a", strings.NewReader("{}"))
+	req.Header.Set(xeroTenantIDHeader, "tenant-1")
+	resp, err := cl.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if body := server.get(t, cl, "tenant-1", "/api.xro/2.0/Accounts"); body != "accounts tenant-1+" {
+		t.Errorf("body after a write = %q, want the new one", body)
+	}
+	server.get(t, cl, "tenant-1", "/api.xro/2.0/Accounts?where=Type%3D%3D%22BANK%22")
+	if body := server.get(t, cl, "tenant-2", "/api.xro/2.0/Accounts"); body != "accounts tenant-2" {
+		t.Errorf("body of the other tenant = %q", body)
+	}
+	if len(server.requests) != 6 {
+		t.Errorf("requests = %d, want the 3 lists, the write and the 2 lists of tenant-1 again", len(server.requests))
+	}
+}
+
+func TestCacheTenants(t *testing.T) {
+	server := newAccountsServer()
+	defer server.Close()
+	cache := NewCache(CacheOptions{TTL: time.Hour})
+	cl := &http.Client{Transport: NewCacheTransport(nil, cache)}
+
+	for _, tenantID := range []string{"tenant-1", "tenant-2"} {
+		if body := server.get(t, cl, tenantID, "/api.xro/2.0/Currencies"); body != "accounts "+tenantID {
+			t.Errorf("body of %s = %q", tenantID, body)
+		}
+	}
+	cache.InvalidateTenant("tenant-1")
+	server.get(t, cl, "tenant-1", "/api.xro/2.0/Currencies")
+	server.get(t, cl, "tenant-2", "/api.xro/2.0/Currencies")
+	if len(server.requests) != 3 {
+		t.Errorf("requests = %d, want one per tenant and one after the invalidation", len(server.requests))
+	}
+}
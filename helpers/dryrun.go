@@ -0,0 +1,138 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// PlannedWrite is a write captured by DryRunTransport instead of being sent,
+// Body is the JSON sent with it, or a JSON string when it wasn't JSON
+type PlannedWrite struct {
+	Method    string          `json:"method"`
+	URL       string          `json:"url"`
+	TenantID  string          `json:"tenantId,omitempty"`
+	Operation string          `json:"operation,omitempty"`
+	Body      json.RawMessage `json:"body,omitempty"`
+}
+
+// Plan keeps the writes captured by DryRunTransport in the order they were
+// made, it's safe for concurrent use
+type Plan struct {
+	mu     sync.Mutex
+	writes []PlannedWrite
+}
+
+// NewPlan function will build a new empty Plan
+func NewPlan() *Plan {
+	return &Plan{}
+}
+
+func (p *Plan) add(write PlannedWrite) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.writes = append(p.writes, write)
+}
+
+// Writes method will return the writes captured so far
+func (p *Plan) Writes() []PlannedWrite {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]PlannedWrite(nil), p.writes...)
+}
+
+// Reset method will drop the writes captured so far
+func (p *Plan) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.writes = nil
+}
+
+// MarshalJSON method will export the plan as a JSON array of writes
+func (p *Plan) MarshalJSON() ([]byte, error) {
+	writes := p.Writes()
+	if writes == nil {
+		writes = []PlannedWrite{}
+	}
+	return json.Marshal(writes)
+}
+
+// WriteJSON method will write the plan as indented JSON to w
+func (p *Plan) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(p)
+}
+
+// DryRunTransport is a http.RoundTripper that lets the GET requests through
+// but captures every write in a Plan instead of sending it. The writes get a
+// synthetic 200 OK response that echoes their body, so the SDK calls that
+// made them succeed
+type DryRunTransport struct {
+	T    http.RoundTripper
+	Plan *Plan
+}
+
+// NewDryRunTransport will build a new DryRunTransport on top of the given
+// transport, if it's nil http.DefaultTransport is used
+func NewDryRunTransport(t http.RoundTripper, plan *Plan) *DryRunTransport {
+	if t == nil {
+		t = http.DefaultTransport
+	}
+	return &DryRunTransport{
+		T:    t,
+		Plan: plan,
+	}
+}
+
+// RoundTrip method will send the reads and capture the writes
+func (dt *DryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return dt.T.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	write := PlannedWrite{
+		Method:   req.Method,
+		URL:      req.URL.String(),
+		TenantID: req.Header.Get(xeroTenantIDHeader),
+	}
+	if op, ok := OperationFromContext(req.Context()); ok {
+		write.Operation = op.String()
+	}
+	switch {
+	case len(body) == 0:
+	case json.Valid(body):
+		write.Body = json.RawMessage(body)
+	default:
+		write.Body, _ = json.Marshal(string(body))
+	}
+	dt.Plan.add(write)
+
+	if !json.Valid(body) {
+		body = []byte("{}")
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
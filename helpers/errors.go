@@ -1,6 +1,28 @@
 package helpers
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrNotFound is matched by an APIError with a 404 status code
+	ErrNotFound = errors.New("xero: resource not found")
+	// ErrUnauthorized is matched by an APIError with a 401 status code
+	ErrUnauthorized = errors.New("xero: unauthorized")
+	// ErrForbidden is matched by an APIError with a 403 status code
+	ErrForbidden = errors.New("xero: forbidden")
+	// ErrRateLimited is matched by an APIError with a 429 status code
+	ErrRateLimited = errors.New("xero: rate limit exceeded")
+	// ErrValidation is matched by an APIError with a 400 status code caused by
+	// a ValidationException or carrying validation errors
+	ErrValidation = errors.New("xero: validation error")
+)
+
+const validationExceptionType = "ValidationException"
 
 // Error is a type that tries to decode the Xero API error, couldn't find anything
 // on the documentation that give me a clear vision about how Xero is managing
@@ -15,6 +37,9 @@ type Error struct {
 
 // DecodeError will try to match the given error into the Error type, if it
 // fails will return a generic Error object
+//
+// Deprecated: every call made through this package already returns an
+// *APIError, use errors.As to get it
 func DecodeError(buf []byte) Error {
 	var e Error
 	if err := json.Unmarshal(buf, &e); err != nil {
@@ -26,3 +51,139 @@ func DecodeError(buf []byte) Error {
 	}
 	return e
 }
+
+// ValidationError is a single validation message returned by Xero
+type ValidationError struct {
+	Message string `json:"Message,omitempty"`
+}
+
+// ElementError keeps the validation errors Xero returned for one element of
+// the request, Index is the position of that element in the request body
+type ElementError struct {
+	Index            int
+	ValidationErrors []ValidationError
+}
+
+// APIError is returned by every call to the Xero API answered with a status
+// code of 400 or above. It can be matched with errors.Is against ErrNotFound,
+// ErrUnauthorized, ErrForbidden, ErrRateLimited and ErrValidation
+type APIError struct {
+	// HTTP status code of the response
+	StatusCode int
+
+	// Method and URL of the request that failed
+	Method string
+	URL    string
+
+	// Accounting API error details
+	ErrorNumber int
+	Type        string
+	Message     string
+
+	// Identity and connections API error details
+	Title    string
+	Detail   string
+	Instance string
+
+	// Elements of the request that failed validation
+	Elements []ElementError
+
+	// Rate limit details, given when the request was rejected with a 429.
+	// RateLimitProblem tells which limit was hit: minute, appminute or day
+	RetryAfter       time.Duration
+	RateLimitProblem string
+
+	// Raw body of the response
+	Body []byte
+}
+
+// newAPIError will build an APIError from the given failed request and
+// response, the body is decoded on a best effort basis as Xero doesn't always
+// answer with JSON
+func newAPIError(request *http.Request, response *http.Response, body []byte) *APIError {
+	e := &APIError{
+		StatusCode: response.StatusCode,
+		Method:     request.Method,
+		URL:        request.URL.String(),
+		Body:       body,
+
+		RateLimitProblem: response.Header.Get(rateLimitProblemHeader),
+	}
+	e.RetryAfter, _ = parseRetryAfter(response.Header.Get(retryAfterHeader))
+	decoded := struct {
+		ErrorNumber int
+		Type        string
+		Message     string
+		Title       string
+		Detail      string
+		Instance    string
+		Elements    []struct {
+			ValidationErrors []ValidationError
+		}
+	}{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return e
+	}
+	e.ErrorNumber = decoded.ErrorNumber
+	e.Type = decoded.Type
+	e.Message = decoded.Message
+	e.Title = decoded.Title
+	e.Detail = decoded.Detail
+	e.Instance = decoded.Instance
+	for index, element := range decoded.Elements {
+		if len(element.ValidationErrors) == 0 {
+			continue
+		}
+		e.Elements = append(e.Elements, ElementError{
+			Index:            index,
+			ValidationErrors: element.ValidationErrors,
+		})
+	}
+	return e
+}
+
+// Error method will return a readable description of the failure
+func (e *APIError) Error() string {
+	description := e.Message
+	if description == "" {
+		description = e.Detail
+	}
+	if description == "" {
+		description = e.Title
+	}
+	if description == "" {
+		description = string(e.Body)
+	}
+	if description == "" {
+		description = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("xero: %s %s returned %d: %s", e.Method, e.URL, e.StatusCode, description)
+}
+
+// Is method will match the error against the sentinel errors of this package
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrValidation:
+		return e.StatusCode == http.StatusBadRequest &&
+			(e.Type == validationExceptionType || len(e.Elements) > 0)
+	}
+	return false
+}
+
+// ValidationErrors will return all the validation messages of the error
+// regardless of the element they belong to
+func (e *APIError) ValidationErrors() []ValidationError {
+	var validationErrors []ValidationError
+	for _, element := range e.Elements {
+		validationErrors = append(validationErrors, element.ValidationErrors...)
+	}
+	return validationErrors
+}
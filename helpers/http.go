@@ -2,14 +2,57 @@ package helpers
 
 import (
 	"bytes"
-	"errors"
+	"context"
+	"io"
 	"io/ioutil"
 	"net/http"
 )
 
 // Find function encapsulate all the GET method calls to Xero API
 func Find(cl *http.Client, endpoint string, additionalHeaders map[string]string, queryParameters map[string]string) ([]byte, error) {
-	request, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	return FindContext(context.Background(), cl, endpoint, additionalHeaders, queryParameters)
+}
+
+// FindContext is the same as Find but the request is bound to the given
+// context
+func FindContext(ctx context.Context, cl *http.Client, endpoint string, additionalHeaders map[string]string, queryParameters map[string]string) ([]byte, error) {
+	request, err := newFindRequest(ctx, endpoint, additionalHeaders, queryParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	return process(cl, request)
+}
+
+// StreamContext is the same as FindContext but instead of reading the whole
+// response, the body is given to fn as it arrives. fn is only called for
+// successful responses, and the body is closed once it returns
+func StreamContext(ctx context.Context, cl *http.Client, endpoint string, additionalHeaders map[string]string, queryParameters map[string]string, fn func(body io.Reader) error) error {
+	request, err := newFindRequest(ctx, endpoint, additionalHeaders, queryParameters)
+	if err != nil {
+		return err
+	}
+	request.Header.Add("Accept", "application/json")
+	resetResponseMeta(request)
+	response, err := cl.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	fillResponseMeta(request, response)
+
+	if response.StatusCode >= http.StatusBadRequest {
+		responseBytes, err := ioutil.ReadAll(response.Body)
+		if err != nil {
+			return err
+		}
+		return newAPIError(request, response, responseBytes)
+	}
+	return fn(response.Body)
+}
+
+func newFindRequest(ctx context.Context, endpoint string, additionalHeaders map[string]string, queryParameters map[string]string) (*http.Request, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -21,37 +64,56 @@ func Find(cl *http.Client, endpoint string, additionalHeaders map[string]string,
 	for key, value := range additionalHeaders {
 		request.Header.Add(key, value)
 	}
-
-	return process(cl, request)
+	return request, nil
 }
 
 // Create function encapsulate all the POST method calls to Xero API
 func Create(cl *http.Client, endpoint string, body []byte) ([]byte, error) {
+	return CreateContext(context.Background(), cl, endpoint, body)
+}
+
+// CreateContext is the same as Create but the request is bound to the given
+// context
+func CreateContext(ctx context.Context, cl *http.Client, endpoint string, body []byte) ([]byte, error) {
 	// We need to use here th PUT method due the constraints from the Xero API
-	request, err := http.NewRequest(http.MethodPut, endpoint, bytes.NewReader(body))
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
 	request.Header.Add("Content-Type", "application/json")
+	setIdempotencyKey(request, body)
 
 	return process(cl, request)
 }
 
 // Update function encapsulate all the PUT method calls to Xero API
 func Update(cl *http.Client, endpoint string, body []byte) ([]byte, error) {
+	return UpdateContext(context.Background(), cl, endpoint, body)
+}
+
+// UpdateContext is the same as Update but the request is bound to the given
+// context
+func UpdateContext(ctx context.Context, cl *http.Client, endpoint string, body []byte) ([]byte, error) {
 	// We need to use here the POST method due the constraints from the Xero API
-	request, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
 	request.Header.Add("Content-Type", "application/json")
+	setIdempotencyKey(request, body)
 
 	return process(cl, request)
 }
 
 // Remove function encapsulate all the DELETE method calls to Xero API
 func Remove(cl *http.Client, endpoint string) ([]byte, error) {
-	request, err := http.NewRequest(http.MethodDelete, endpoint, nil)
+	return RemoveContext(context.Background(), cl, endpoint)
+}
+
+// RemoveContext is the same as Remove but the request is bound to the given
+// context
+func RemoveContext(ctx context.Context, cl *http.Client, endpoint string) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodDelete, endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -61,18 +123,20 @@ func Remove(cl *http.Client, endpoint string) ([]byte, error) {
 
 func process(cl *http.Client, request *http.Request) ([]byte, error) {
 	request.Header.Add("Accept", "application/json")
+	resetResponseMeta(request)
 	response, err := cl.Do(request)
 	if err != nil {
 		return nil, err
 	}
 	defer response.Body.Close()
+	fillResponseMeta(request, response)
 
 	responseBytes, err := ioutil.ReadAll(response.Body)
 	if err != nil {
 		return nil, err
 	}
 	if response.StatusCode >= http.StatusBadRequest {
-		return nil, errors.New(string(responseBytes))
+		return nil, newAPIError(request, response, responseBytes)
 	}
 	return responseBytes, nil
 }
@@ -0,0 +1,140 @@
+package helpers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+
+	// keyHashLength is the number of hex characters of the request hash added
+	// to the keys given with WithIdempotencyKey
+	keyHashLength = 16
+)
+
+type contextKey int
+
+const (
+	idempotencyKeyContextKey contextKey = iota
+	responseMetaContextKey
+	operationContextKey
+	requestIDContextKey
+)
+
+// generateIdempotencyKey is stored in the context instead of a key when the
+// caller asked for a generated one
+type generateIdempotencyKey struct{}
+
+// WithIdempotencyKey function will return a copy of the context that makes
+// the Create and Update calls send an Idempotency-Key header made of the given
+// key and a hash of the method, URL and body of the request. Xero will answer
+// with the result of the first call for any other call with the same key,
+// which makes retries safe, and as one key means one write the different
+// writes made with the context get different keys. ResponseMeta tells the key
+// sent
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey, key)
+}
+
+// IdempotencyKey function will return the key given to the context with
+// WithIdempotencyKey, or an empty string
+func IdempotencyKey(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyContextKey).(string)
+	return key
+}
+
+// WithGeneratedIdempotencyKey function will return a copy of the context that
+// makes the Create and Update calls send an Idempotency-Key header generated
+// from the method, URL and body of the request. Two calls with the same
+// content will get the same key, so it must only be used when sending the same
+// content twice means sending it once
+func WithGeneratedIdempotencyKey(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey, generateIdempotencyKey{})
+}
+
+// setIdempotencyKey will add the Idempotency-Key header to the request when
+// the context asks for it
+func setIdempotencyKey(request *http.Request, body []byte) {
+	var key string
+	switch value := request.Context().Value(idempotencyKeyContextKey).(type) {
+	case string:
+		if value != "" {
+			key = value + "-" + deterministicKey(request.Method, request.URL.String(), body)[:keyHashLength]
+		}
+	case generateIdempotencyKey:
+		key = deterministicKey(request.Method, request.URL.String(), body)
+	}
+	if key != "" {
+		request.Header.Set(idempotencyKeyHeader, key)
+	}
+}
+
+func deterministicKey(method string, url string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + "\n" + url + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ResponseMeta keeps the information about the response of a call that is
+// not part of the returned value
+type ResponseMeta struct {
+	// StatusCode and Header of the last response
+	StatusCode int
+	Header     http.Header
+
+	// IdempotencyKey sent with the request, if any
+	IdempotencyKey string
+
+	// Attempts is the number of times the request was sent
+	Attempts int
+
+	// Replayed is true when Xero answered with the stored result of a
+	// previous call with the same idempotency key
+	Replayed bool
+
+	// PossibleReplay is true when an earlier attempt with the same idempotency
+	// key failed after the request was sent, Xero could have processed it and
+	// the response could be a replay of that attempt
+	PossibleReplay bool
+}
+
+// WithResponseMeta function will return a copy of the context that makes the
+// calls fill the given ResponseMeta
+func WithResponseMeta(ctx context.Context, meta *ResponseMeta) context.Context {
+	return context.WithValue(ctx, responseMetaContextKey, meta)
+}
+
+func responseMetaFromContext(ctx context.Context) *ResponseMeta {
+	meta, _ := ctx.Value(responseMetaContextKey).(*ResponseMeta)
+	return meta
+}
+
+// resetResponseMeta will clear the ResponseMeta of the request context, if
+// any, so it can be reused between calls
+func resetResponseMeta(request *http.Request) {
+	if meta := responseMetaFromContext(request.Context()); meta != nil {
+		*meta = ResponseMeta{}
+	}
+}
+
+// fillResponseMeta will store the details of the response on the
+// ResponseMeta of the request context, if any
+func fillResponseMeta(request *http.Request, response *http.Response) {
+	meta := responseMetaFromContext(request.Context())
+	if meta == nil {
+		return
+	}
+	meta.StatusCode = response.StatusCode
+	meta.Header = response.Header
+	meta.IdempotencyKey = request.Header.Get(idempotencyKeyHeader)
+	if meta.Attempts == 0 {
+		meta.Attempts = 1
+	}
+	meta.Replayed = strings.EqualFold(response.Header.Get(idempotentReplayedHeader), "true")
+}
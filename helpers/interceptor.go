@@ -0,0 +1,218 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const (
+	requestIDHeader     = "X-Request-ID"
+	correlationIDHeader = "Xero-Correlation-Id"
+	xeroTenantIDHeader  = "Xero-Tenant-Id"
+)
+
+// Operation describes the SDK call a request belongs to, Resource is the
+// resource of the API, such as Invoices, and Name is the action, such as List
+// or Create
+type Operation struct {
+	Resource string
+	Name     string
+}
+
+// String method will return the operation as Resource.Name
+func (o Operation) String() string {
+	if o.Resource == "" && o.Name == "" {
+		return "unknown"
+	}
+	return o.Resource + "." + o.Name
+}
+
+// WithOperation function will return a copy of the context that tells the
+// interceptors which operation the request belongs to
+func WithOperation(ctx context.Context, op Operation) context.Context {
+	return context.WithValue(ctx, operationContextKey, op)
+}
+
+// OperationFromContext function will return the operation stored in the
+// context, if any
+func OperationFromContext(ctx context.Context) (Operation, bool) {
+	op, ok := ctx.Value(operationContextKey).(Operation)
+	return op, ok
+}
+
+// RequestIDFromContext function will return the request ID set by the
+// RequestIDInterceptor, if any
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey).(string)
+	return id
+}
+
+// RoundTripFunc is a function that can be used as a http.RoundTripper
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip method will call the function
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Interceptor is called for every request made to Xero, it must call next to
+// send the request and can change the request before and inspect the response
+// after
+type Interceptor func(req *http.Request, next RoundTripFunc) (*http.Response, error)
+
+// InterceptorTransport is a http.RoundTripper that sends each request through
+// a chain of interceptors, the first one is the outermost
+type InterceptorTransport struct {
+	T            http.RoundTripper
+	Interceptors []Interceptor
+}
+
+// NewInterceptorTransport will build a new InterceptorTransport on top of the
+// given transport, if it's nil http.DefaultTransport is used
+func NewInterceptorTransport(t http.RoundTripper, interceptors ...Interceptor) *InterceptorTransport {
+	if t == nil {
+		t = http.DefaultTransport
+	}
+	return &InterceptorTransport{
+		T:            t,
+		Interceptors: interceptors,
+	}
+}
+
+// RoundTrip method will send the request through the interceptors
+func (it *InterceptorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := RoundTripFunc(it.T.RoundTrip)
+	for i := len(it.Interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := it.Interceptors[i], next
+		next = func(r *http.Request) (*http.Response, error) {
+			return interceptor(r, inner)
+		}
+	}
+	return next(req)
+}
+
+// Logger is the minimum logger needed by the LoggingInterceptor, *log.Logger
+// satisfies it
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// RequestIDInterceptor will give each request an unique ID, it's sent on the
+// X-Request-ID header and stored on the request context. A request that
+// already has the header keeps its ID
+func RequestIDInterceptor() Interceptor {
+	return func(req *http.Request, next RoundTripFunc) (*http.Response, error) {
+		id := req.Header.Get(requestIDHeader)
+		if id == "" {
+			id = newRequestID()
+		}
+		r := req.Clone(context.WithValue(req.Context(), requestIDContextKey, id))
+		r.Header.Set(requestIDHeader, id)
+		return next(r)
+	}
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
+
+// MaxLoggedBody is the size of the largest body logged by the
+// LoggingInterceptor, the larger ones are only reported with their size
+const MaxLoggedBody = 64 << 10
+
+// Timing keeps the outcome of a request measured by the TimingInterceptor,
+// the URL has the query redacted with RedactURL
+type Timing struct {
+	Operation  Operation
+	Method     string
+	URL        string
+	StatusCode int
+	Duration   time.Duration
+	Err        error
+}
+
+// TimingInterceptor will call record with the duration and outcome of each
+// request
+func TimingInterceptor(record func(Timing)) Interceptor {
+	return func(req *http.Request, next RoundTripFunc) (*http.Response, error) {
+		start := time.Now()
+		response, err := next(req)
+		op, _ := OperationFromContext(req.Context())
+		t := Timing{
+			Operation: op,
+			Method:    req.Method,
+			URL:       RedactURL(req.URL),
+			Duration:  time.Since(start),
+			Err:       err,
+		}
+		if response != nil {
+			t.StatusCode = response.StatusCode
+		}
+		record(t)
+		return response, err
+	}
+}
+
+// LoggingInterceptor will log each request and its response with the given
+// logger. The headers are always redacted with RedactHeaders and the URL with
+// RedactURL, when bodies is true the request and response bodies up to
+// MaxLoggedBody are logged too, redacted with RedactBody. The larger response
+// bodies, such as the streamed pages, are not read ahead
+func LoggingInterceptor(logger Logger, bodies bool) Interceptor {
+	return func(req *http.Request, next RoundTripFunc) (*http.Response, error) {
+		op, _ := OperationFromContext(req.Context())
+		id := RequestIDFromContext(req.Context())
+		logger.Printf("xero: request id=%s op=%s %s %s headers=%v", id, op, req.Method, RedactURL(req.URL), RedactHeaders(req.Header))
+		if bodies && req.GetBody != nil {
+			if body, err := req.GetBody(); err == nil {
+				buf, _ := ioutil.ReadAll(io.LimitReader(body, MaxLoggedBody+1))
+				body.Close()
+				logger.Printf("xero: request id=%s body=%s", id, loggedBody(buf))
+			}
+		}
+
+		start := time.Now()
+		response, err := next(req)
+		elapsed := time.Since(start)
+		if err != nil {
+			logger.Printf("xero: response id=%s op=%s error=%v duration=%s", id, op, err, elapsed)
+			return response, err
+		}
+		logger.Printf("xero: response id=%s op=%s status=%d correlation=%s duration=%s headers=%v",
+			id, op, response.StatusCode, response.Header.Get(correlationIDHeader), elapsed, RedactHeaders(response.Header))
+		if bodies && response.Body != nil {
+			buf, readErr := ioutil.ReadAll(io.LimitReader(response.Body, MaxLoggedBody+1))
+			if readErr != nil {
+				response.Body.Close()
+				return nil, readErr
+			}
+			// The caller reads what was logged and then the rest of the body
+			response.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(buf), response.Body), response.Body}
+			logger.Printf("xero: response id=%s body=%s", id, loggedBody(buf))
+		}
+		return response, nil
+	}
+}
+
+// loggedBody will return the body to log, read with a limit of one byte over
+// MaxLoggedBody
+func loggedBody(buf []byte) []byte {
+	if len(buf) > MaxLoggedBody {
+		return []byte(fmt.Sprintf("[over %d bytes, not logged]", MaxLoggedBody))
+	}
+	return RedactBody(buf)
+}
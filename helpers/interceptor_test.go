@@ -0,0 +1,79 @@
+package helpers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// bufferLogger keeps the lines logged
+type bufferLogger struct {
+	lines []string
+}
+
+func (l *bufferLogger) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func TestRedactURL(t *testing.T) {
+	u, _ := url.Parse(`https://api.xero.com/api.xro/2.0/Contacts?page=2&where=EmailAddress%3D%3D%22a%40example.com%22&IDs=1,2`)
+	got := RedactURL(u)
+	want := "https://api.xero.com/api.xro/2.0/Contacts?IDs=%5BREDACTED%5D&page=2&where=%5BREDACTED%5D"
+	if got != want {
+		t.Errorf("RedactURL = %s, want %s", got, want)
+	}
+	if u.RawQuery == "" || !strings.Contains(u.RawQuery, "example.com") {
+		t.Errorf("RedactURL changed the URL to %s", u)
+	}
+}
+
+func TestLoggingInterceptor(t *testing.T) {
+	large := `{"Contacts":[` + strings.Repeat(`{"Name":"contact"},`, MaxLoggedBody/10) + `{}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			w.Write([]byte(large))
+			return
+		}
+		w.Write([]byte(`{"Contacts":[{"Name":"contact","EmailAddress":"a@example.com"}]}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		page string
+		body string
+		want string
+	}{
+		{"small body", "1", `{"Contacts":[{"Name":"contact","EmailAddress":"a@example.com"}]}`, `body={"Contacts":[{"EmailAddress":"[REDACTED]","Name":"contact"}]}`},
+		{"large body", "2", large, fmt.Sprintf("body=[over %d bytes, not logged]", MaxLoggedBody)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &bufferLogger{}
+			cl := &http.Client{Transport: NewInterceptorTransport(nil, LoggingInterceptor(logger, true))}
+			resp, err := cl.Get(server.URL + "/Contacts?page=" + tt.page + "&where=" + url.QueryEscape(`Name=="a@example.com"`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != tt.body {
+				t.Errorf("body read by the caller = %d bytes, want %d", len(body), len(tt.body))
+			}
+			logged := strings.Join(logger.lines, "\n")
+			if strings.Contains(logged, "example.com") {
+				t.Errorf("logs have personal data:\n%s", logged)
+			}
+			if !strings.Contains(logged, tt.want) {
+				t.Errorf("logs = %s, want %s", logged, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package helpers
+
+import (
+	"bufio"
+	"context"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the latency histogram
+var latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type endpointKey struct {
+	tenant   string
+	endpoint string
+}
+
+type endpointStats struct {
+	requests     int64
+	errors       int64
+	retries      int64
+	latencySum   float64
+	latencyCount []int64
+}
+
+// Metrics is an in-process Observer that keeps per tenant and per endpoint
+// counters, they can be exposed in the Prometheus text format or with expvar
+type Metrics struct {
+	mu sync.Mutex
+
+	endpoints map[endpointKey]*endpointStats
+
+	// remaining quota per tenant, as reported by Xero
+	rateLimits map[string]RateLimits
+
+	tokenRefreshes      int64
+	tokenRefreshErrors  int64
+	webhooks            int64
+	webhookInvalidCalls int64
+}
+
+// NewMetrics function will build a new empty Metrics
+func NewMetrics() *Metrics {
+	return &Metrics{
+		endpoints:  make(map[endpointKey]*endpointStats),
+		rateLimits: make(map[string]RateLimits),
+	}
+}
+
+func (m *Metrics) stats(info RequestInfo) *endpointStats {
+	key := endpointKey{tenant: info.TenantID, endpoint: info.Operation.String()}
+	s, ok := m.endpoints[key]
+	if !ok {
+		s = &endpointStats{latencyCount: make([]int64, len(latencyBuckets))}
+		m.endpoints[key] = s
+	}
+	return s
+}
+
+// OnRequestStart method does nothing, requests are counted when they end
+func (m *Metrics) OnRequestStart(context.Context, RequestInfo) {}
+
+// OnRequestEnd method will count the request, its latency and the rate limit
+// quota reported by Xero
+func (m *Metrics) OnRequestEnd(_ context.Context, result RequestResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.stats(result.RequestInfo)
+	s.requests++
+	if result.Err != nil || result.StatusCode >= http.StatusBadRequest {
+		s.errors++
+	}
+	seconds := result.Duration.Seconds()
+	s.latencySum += seconds
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			s.latencyCount[i]++
+		}
+	}
+
+	if result.Header == nil {
+		return
+	}
+	previous, ok := m.rateLimits[result.TenantID]
+	if !ok {
+		previous = unknownRateLimits(result.TenantID)
+	}
+	if limits, ok := rateLimitsFromHeader(previous, result.Header); ok {
+		m.rateLimits[result.TenantID] = limits
+	}
+}
+
+// OnRetry method will count the retry
+func (m *Metrics) OnRetry(_ context.Context, info RetryInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stats(info.RequestInfo).retries++
+}
+
+// OnTokenRefresh method will count the refresh
+func (m *Metrics) OnTokenRefresh(_ context.Context, info TokenRefreshInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokenRefreshes++
+	if info.Err != nil {
+		m.tokenRefreshErrors++
+	}
+}
+
+// OnWebhook method will count the webhook
+func (m *Metrics) OnWebhook(_ context.Context, info WebhookInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.webhooks++
+	if !info.Valid {
+		m.webhookInvalidCalls++
+	}
+}
+
+func (m *Metrics) sortedKeys() []endpointKey {
+	keys := make([]endpointKey, 0, len(m.endpoints))
+	for key := range m.endpoints {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].tenant != keys[j].tenant {
+			return keys[i].tenant < keys[j].tenant
+		}
+		return keys[i].endpoint < keys[j].endpoint
+	})
+	return keys
+}
+
+// WritePrometheus method will write the metrics in the Prometheus text format
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b := bufio.NewWriter(w)
+	keys := m.sortedKeys()
+
+	fmt.Fprintln(b, "# HELP xero_requests_total Requests made to the Xero API.")
+	fmt.Fprintln(b, "# TYPE xero_requests_total counter")
+	for _, key := range keys {
+		fmt.Fprintf(b, "xero_requests_total{tenant=%q,endpoint=%q} %d\n", key.tenant, key.endpoint, m.endpoints[key].requests)
+	}
+	fmt.Fprintln(b, "# HELP xero_request_errors_total Requests to the Xero API that failed.")
+	fmt.Fprintln(b, "# TYPE xero_request_errors_total counter")
+	for _, key := range keys {
+		fmt.Fprintf(b, "xero_request_errors_total{tenant=%q,endpoint=%q} %d\n", key.tenant, key.endpoint, m.endpoints[key].errors)
+	}
+	fmt.Fprintln(b, "# HELP xero_request_retries_total Retries of requests to the Xero API.")
+	fmt.Fprintln(b, "# TYPE xero_request_retries_total counter")
+	for _, key := range keys {
+		fmt.Fprintf(b, "xero_request_retries_total{tenant=%q,endpoint=%q} %d\n", key.tenant, key.endpoint, m.endpoints[key].retries)
+	}
+	fmt.Fprintln(b, "# HELP xero_request_duration_seconds Latency of the requests to the Xero API.")
+	fmt.Fprintln(b, "# TYPE xero_request_duration_seconds histogram")
+	for _, key := range keys {
+		s := m.endpoints[key]
+		for i, bound := range latencyBuckets {
+			fmt.Fprintf(b, "xero_request_duration_seconds_bucket{tenant=%q,endpoint=%q,le=%q} %d\n",
+				key.tenant, key.endpoint, strconv.FormatFloat(bound, 'f', -1, 64), s.latencyCount[i])
+		}
+		fmt.Fprintf(b, "xero_request_duration_seconds_bucket{tenant=%q,endpoint=%q,le=\"+Inf\"} %d\n", key.tenant, key.endpoint, s.requests)
+		fmt.Fprintf(b, "xero_request_duration_seconds_sum{tenant=%q,endpoint=%q} %g\n", key.tenant, key.endpoint, s.latencySum)
+		fmt.Fprintf(b, "xero_request_duration_seconds_count{tenant=%q,endpoint=%q} %d\n", key.tenant, key.endpoint, s.requests)
+	}
+
+	tenants := make([]string, 0, len(m.rateLimits))
+	for tenant := range m.rateLimits {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	fmt.Fprintln(b, "# HELP xero_rate_limit_remaining Remaining quota reported by the Xero API.")
+	fmt.Fprintln(b, "# TYPE xero_rate_limit_remaining gauge")
+	for _, tenant := range tenants {
+		limits := m.rateLimits[tenant]
+		for _, limit := range []struct {
+			name      string
+			remaining int
+		}{{"appminute", limits.AppMinute}, {"day", limits.Day}, {"minute", limits.Minute}} {
+			if limit.remaining >= 0 {
+				fmt.Fprintf(b, "xero_rate_limit_remaining{tenant=%q,limit=%q} %d\n", tenant, limit.name, limit.remaining)
+			}
+		}
+	}
+
+	fmt.Fprintln(b, "# HELP xero_token_refreshes_total Token refreshes.")
+	fmt.Fprintln(b, "# TYPE xero_token_refreshes_total counter")
+	fmt.Fprintf(b, "xero_token_refreshes_total %d\n", m.tokenRefreshes)
+	fmt.Fprintln(b, "# HELP xero_token_refresh_errors_total Token refreshes that failed.")
+	fmt.Fprintln(b, "# TYPE xero_token_refresh_errors_total counter")
+	fmt.Fprintf(b, "xero_token_refresh_errors_total %d\n", m.tokenRefreshErrors)
+	fmt.Fprintln(b, "# HELP xero_webhooks_total Webhook requests received.")
+	fmt.Fprintln(b, "# TYPE xero_webhooks_total counter")
+	fmt.Fprintf(b, "xero_webhooks_total %d\n", m.webhooks)
+	fmt.Fprintln(b, "# HELP xero_webhook_invalid_total Webhook requests received with an invalid signature.")
+	fmt.Fprintln(b, "# TYPE xero_webhook_invalid_total counter")
+	fmt.Fprintf(b, "xero_webhook_invalid_total %d\n", m.webhookInvalidCalls)
+
+	return b.Flush()
+}
+
+// ServeHTTP method will expose the metrics in the Prometheus text format, so
+// Metrics can be mounted as the /metrics handler
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	m.WritePrometheus(w)
+}
+
+// Var method will return the metrics as an expvar.Var, it can be published
+// with expvar.Publish
+func (m *Metrics) Var() expvar.Var {
+	return expvar.Func(m.snapshot)
+}
+
+func (m *Metrics) snapshot() interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	type endpoint struct {
+		Tenant         string
+		Endpoint       string
+		Requests       int64
+		Errors         int64
+		Retries        int64
+		LatencySeconds float64
+	}
+	endpoints := make([]endpoint, 0, len(m.endpoints))
+	for _, key := range m.sortedKeys() {
+		s := m.endpoints[key]
+		endpoints = append(endpoints, endpoint{
+			Tenant:         key.tenant,
+			Endpoint:       key.endpoint,
+			Requests:       s.requests,
+			Errors:         s.errors,
+			Retries:        s.retries,
+			LatencySeconds: s.latencySum,
+		})
+	}
+	rateLimits := make(map[string]RateLimits, len(m.rateLimits))
+	for tenant, limits := range m.rateLimits {
+		rateLimits[tenant] = limits
+	}
+	return map[string]interface{}{
+		"endpoints":          endpoints,
+		"rateLimits":         rateLimits,
+		"tokenRefreshes":     m.tokenRefreshes,
+		"tokenRefreshErrors": m.tokenRefreshErrors,
+		"webhooks":           m.webhooks,
+		"webhookInvalid":     m.webhookInvalidCalls,
+	}
+}
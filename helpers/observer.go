@@ -0,0 +1,127 @@
+package helpers
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// RequestInfo describes a request made to Xero, the URL has the query
+// redacted with RedactURL
+type RequestInfo struct {
+	TenantID  string
+	Operation Operation
+	Method    string
+	URL       string
+}
+
+// RequestResult describes the outcome of a request made to Xero, Header is the
+// header of the response and it's nil when the request failed without one
+type RequestResult struct {
+	RequestInfo
+	StatusCode int
+	Header     http.Header
+	Duration   time.Duration
+	Err        error
+}
+
+// RetryInfo describes a retry made by RetryTransport, Attempt is the attempt
+// that failed and Delay is the wait before the next one
+type RetryInfo struct {
+	RequestInfo
+	Attempt    int
+	Delay      time.Duration
+	StatusCode int
+	Err        error
+}
+
+// TokenRefreshInfo describes a refresh of the token of a user
+type TokenRefreshInfo struct {
+	UserID   string
+	Duration time.Duration
+	Err      error
+}
+
+// WebhookInfo describes a webhook request received from Xero, Valid is false
+// when the signature didn't match
+type WebhookInfo struct {
+	Valid bool
+}
+
+// Observer is called by the SDK on the events needed to monitor it, the
+// methods are called synchronously so they must be fast and safe for
+// concurrent use
+type Observer interface {
+	OnRequestStart(ctx context.Context, info RequestInfo)
+	OnRequestEnd(ctx context.Context, result RequestResult)
+	OnRetry(ctx context.Context, info RetryInfo)
+	OnTokenRefresh(ctx context.Context, info TokenRefreshInfo)
+	OnWebhook(ctx context.Context, info WebhookInfo)
+}
+
+// NopObserver is an Observer that does nothing, it can be embedded to
+// implement only some of the methods
+type NopObserver struct{}
+
+// OnRequestStart method does nothing
+func (NopObserver) OnRequestStart(context.Context, RequestInfo) {}
+
+// OnRequestEnd method does nothing
+func (NopObserver) OnRequestEnd(context.Context, RequestResult) {}
+
+// OnRetry method does nothing
+func (NopObserver) OnRetry(context.Context, RetryInfo) {}
+
+// OnTokenRefresh method does nothing
+func (NopObserver) OnTokenRefresh(context.Context, TokenRefreshInfo) {}
+
+// OnWebhook method does nothing
+func (NopObserver) OnWebhook(context.Context, WebhookInfo) {}
+
+func newRequestInfo(req *http.Request) RequestInfo {
+	op, _ := OperationFromContext(req.Context())
+	return RequestInfo{
+		TenantID:  req.Header.Get(xeroTenantIDHeader),
+		Operation: op,
+		Method:    req.Method,
+		URL:       RedactURL(req.URL),
+	}
+}
+
+// ObserverTransport is a http.RoundTripper that tells the Observer when each
+// request starts and ends
+type ObserverTransport struct {
+	T        http.RoundTripper
+	Observer Observer
+}
+
+// NewObserverTransport will build a new ObserverTransport on top of the given
+// transport, if it's nil http.DefaultTransport is used
+func NewObserverTransport(t http.RoundTripper, observer Observer) *ObserverTransport {
+	if t == nil {
+		t = http.DefaultTransport
+	}
+	return &ObserverTransport{
+		T:        t,
+		Observer: observer,
+	}
+}
+
+// RoundTrip method will send the request telling the Observer about it
+func (ot *ObserverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	info := newRequestInfo(req)
+	ot.Observer.OnRequestStart(req.Context(), info)
+	start := time.Now()
+	response, err := ot.T.RoundTrip(req)
+	result := RequestResult{
+		RequestInfo: info,
+		Duration:    time.Since(start),
+		Err:         err,
+	}
+	if response != nil {
+		result.StatusCode = response.StatusCode
+		result.Header = response.Header
+	}
+	ot.Observer.OnRequestEnd(req.Context(), result)
+	return response, err
+}
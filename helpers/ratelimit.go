@@ -0,0 +1,172 @@
+package helpers
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Rate limit headers sent by Xero on each response
+const (
+	dayLimitRemainingHeader       = "X-DayLimit-Remaining"
+	minuteLimitRemainingHeader    = "X-MinLimit-Remaining"
+	appMinuteLimitRemainingHeader = "X-AppMinLimit-Remaining"
+)
+
+// RateLimits keeps the remaining quota of a tenant as reported by Xero on its
+// last response. A value of -1 means Xero didn't report that limit yet
+type RateLimits struct {
+	TenantID string
+
+	// Day is the number of calls left for the tenant today
+	Day int
+	// Minute is the number of calls left for the tenant in the current minute
+	Minute int
+	// AppMinute is the number of calls left for the whole app in the current
+	// minute
+	AppMinute int
+
+	UpdatedAt time.Time
+}
+
+// rateLimitsFromHeader will read the rate limit headers of a response, the
+// limits missing on it are taken from previous. ok is false when the response
+// has none of them
+func rateLimitsFromHeader(previous RateLimits, header http.Header) (limits RateLimits, ok bool) {
+	limits = previous
+	for name, value := range map[string]*int{
+		dayLimitRemainingHeader:       &limits.Day,
+		minuteLimitRemainingHeader:    &limits.Minute,
+		appMinuteLimitRemainingHeader: &limits.AppMinute,
+	} {
+		remaining, err := strconv.Atoi(header.Get(name))
+		if err != nil {
+			continue
+		}
+		*value = remaining
+		ok = true
+	}
+	return limits, ok
+}
+
+func unknownRateLimits(tenantID string) RateLimits {
+	return RateLimits{
+		TenantID:  tenantID,
+		Day:       -1,
+		Minute:    -1,
+		AppMinute: -1,
+	}
+}
+
+// RateLimitTracker keeps the latest rate limits reported by Xero for each
+// tenant, it's safe for concurrent use
+type RateLimitTracker struct {
+	mu          sync.RWMutex
+	limits      map[string]RateLimits
+	subscribers map[int]func(RateLimits)
+	nextID      int
+}
+
+// NewRateLimitTracker function will build a new empty RateLimitTracker
+func NewRateLimitTracker() *RateLimitTracker {
+	return &RateLimitTracker{
+		limits:      make(map[string]RateLimits),
+		subscribers: make(map[int]func(RateLimits)),
+	}
+}
+
+// Remaining method will return the latest rate limits of the given tenant,
+// ok is false when no response was seen for it yet
+func (t *RateLimitTracker) Remaining(tenantID string) (limits RateLimits, ok bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	limits, ok = t.limits[tenantID]
+	if !ok {
+		return unknownRateLimits(tenantID), false
+	}
+	return limits, true
+}
+
+// All method will return the latest rate limits of all the tenants seen
+func (t *RateLimitTracker) All() []RateLimits {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	all := make([]RateLimits, 0, len(t.limits))
+	for _, limits := range t.limits {
+		all = append(all, limits)
+	}
+	return all
+}
+
+// Subscribe method will call fn each time the rate limits of a tenant are
+// updated, fn is called synchronously so it must be fast. The returned
+// function removes the subscription
+func (t *RateLimitTracker) Subscribe(fn func(RateLimits)) (unsubscribe func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	id := t.nextID
+	t.nextID++
+	t.subscribers[id] = fn
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		delete(t.subscribers, id)
+	}
+}
+
+// Update method will store the rate limits found on the given response
+// headers for the given tenant
+func (t *RateLimitTracker) Update(tenantID string, header http.Header) {
+	t.mu.Lock()
+	previous, ok := t.limits[tenantID]
+	if !ok {
+		previous = unknownRateLimits(tenantID)
+	}
+	limits, ok := rateLimitsFromHeader(previous, header)
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+	limits.UpdatedAt = time.Now()
+	t.limits[tenantID] = limits
+	subscribers := make([]func(RateLimits), 0, len(t.subscribers))
+	for _, fn := range t.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	t.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(limits)
+	}
+}
+
+// RateLimitTransport is a http.RoundTripper that feeds a RateLimitTracker
+// with the headers of each response, the tenant is taken from the
+// xero-tenant-id header of the request
+type RateLimitTransport struct {
+	T       http.RoundTripper
+	Tracker *RateLimitTracker
+}
+
+// NewRateLimitTransport will build a new RateLimitTransport on top of the
+// given transport, if it's nil http.DefaultTransport is used
+func NewRateLimitTransport(t http.RoundTripper, tracker *RateLimitTracker) *RateLimitTransport {
+	if t == nil {
+		t = http.DefaultTransport
+	}
+	return &RateLimitTransport{
+		T:       t,
+		Tracker: tracker,
+	}
+}
+
+// RoundTrip method will send the request and track the rate limits of the
+// response
+func (rt *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	response, err := rt.T.RoundTrip(req)
+	if err == nil {
+		rt.Tracker.Update(req.Header.Get(xeroTenantIDHeader), response.Header)
+	}
+	return response, err
+}
@@ -0,0 +1,151 @@
+package helpers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// rateLimitServer answers each request with the rate limit headers given in
+// the query of the request, so each call decides what Xero reports
+func rateLimitServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, name := range []string{dayLimitRemainingHeader, minuteLimitRemainingHeader, appMinuteLimitRemainingHeader} {
+			if value := r.URL.Query().Get(name); value != "" {
+				w.Header().Set(name, value)
+			}
+		}
+	}))
+}
+
+func callWithLimits(t *testing.T, cl *http.Client, url string, tenantID string, limits map[string]string) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := req.URL.Query()
+	for name, value := range limits {
+		query.Set(name, value)
+	}
+	req.URL.RawQuery = query.Encode()
+	if tenantID != "" {
+		req.Header.Set(xeroTenantIDHeader, tenantID)
+	}
+	resp, err := cl.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
+
+func assertLimits(t *testing.T, got RateLimits, day, minute, appMinute int) {
+	t.Helper()
+	if got.Day != day || got.Minute != minute || got.AppMinute != appMinute {
+		t.Errorf("limits = %d/%d/%d, want %d/%d/%d", got.Day, got.Minute, got.AppMinute, day, minute, appMinute)
+	}
+}
+
+func TestRateLimitTrackerRemaining(t *testing.T) {
+	server := rateLimitServer()
+	defer server.Close()
+	tracker := NewRateLimitTracker()
+	cl := &http.Client{Transport: NewRateLimitTransport(nil, tracker)}
+
+	if limits, ok := tracker.Remaining("tenant-1"); ok {
+		t.Errorf("Remaining of an unknown tenant = %+v, want not ok", limits)
+	} else {
+		assertLimits(t, limits, -1, -1, -1)
+	}
+
+	callWithLimits(t, cl, server.URL, "tenant-1", map[string]string{
+		dayLimitRemainingHeader:       "4990",
+		minuteLimitRemainingHeader:    "58",
+		appMinuteLimitRemainingHeader: "9990",
+	})
+	limits, ok := tracker.Remaining("tenant-1")
+	if !ok {
+		t.Fatal("Remaining of tenant-1 not ok")
+	}
+	assertLimits(t, limits, 4990, 58, 9990)
+	if limits.TenantID != "tenant-1" || limits.UpdatedAt.IsZero() {
+		t.Errorf("limits = %+v, want tenant-1 with UpdatedAt", limits)
+	}
+}
+
+func TestRateLimitTrackerPartialHeaders(t *testing.T) {
+	server := rateLimitServer()
+	defer server.Close()
+	tracker := NewRateLimitTracker()
+	cl := &http.Client{Transport: NewRateLimitTransport(nil, tracker)}
+
+	callWithLimits(t, cl, server.URL, "tenant-1", map[string]string{
+		dayLimitRemainingHeader:       "4990",
+		minuteLimitRemainingHeader:    "58",
+		appMinuteLimitRemainingHeader: "9990",
+	})
+	callWithLimits(t, cl, server.URL, "tenant-1", map[string]string{
+		minuteLimitRemainingHeader: "57",
+	})
+	limits, _ := tracker.Remaining("tenant-1")
+	assertLimits(t, limits, 4990, 57, 9990)
+
+	// A response with no rate limit headers keeps the previous values
+	callWithLimits(t, cl, server.URL, "tenant-1", nil)
+	limits, _ = tracker.Remaining("tenant-1")
+	assertLimits(t, limits, 4990, 57, 9990)
+}
+
+func TestRateLimitTrackerTenants(t *testing.T) {
+	server := rateLimitServer()
+	defer server.Close()
+	tracker := NewRateLimitTracker()
+	cl := &http.Client{Transport: NewRateLimitTransport(nil, tracker)}
+
+	callWithLimits(t, cl, server.URL, "tenant-1", map[string]string{
+		dayLimitRemainingHeader:    "100",
+		minuteLimitRemainingHeader: "10",
+	})
+	callWithLimits(t, cl, server.URL, "tenant-2", map[string]string{
+		dayLimitRemainingHeader:    "200",
+		minuteLimitRemainingHeader: "20",
+	})
+
+	first, _ := tracker.Remaining("tenant-1")
+	assertLimits(t, first, 100, 10, -1)
+	second, _ := tracker.Remaining("tenant-2")
+	assertLimits(t, second, 200, 20, -1)
+	if all := tracker.All(); len(all) != 2 {
+		t.Errorf("All = %d tenants, want 2", len(all))
+	}
+}
+
+func TestRateLimitTrackerSubscribe(t *testing.T) {
+	server := rateLimitServer()
+	defer server.Close()
+	tracker := NewRateLimitTracker()
+	cl := &http.Client{Transport: NewRateLimitTransport(nil, tracker)}
+
+	var updates []RateLimits
+	unsubscribe := tracker.Subscribe(func(limits RateLimits) {
+		updates = append(updates, limits)
+	})
+
+	callWithLimits(t, cl, server.URL, "tenant-1", map[string]string{minuteLimitRemainingHeader: "59"})
+	callWithLimits(t, cl, server.URL, "tenant-1", nil)
+	callWithLimits(t, cl, server.URL, "tenant-2", map[string]string{minuteLimitRemainingHeader: "58"})
+	if len(updates) != 2 {
+		t.Fatalf("updates = %d, want 2", len(updates))
+	}
+	if updates[0].TenantID != "tenant-1" || updates[0].Minute != 59 {
+		t.Errorf("first update = %+v, want tenant-1 with 59", updates[0])
+	}
+	if updates[1].TenantID != "tenant-2" || updates[1].Minute != 58 {
+		t.Errorf("second update = %+v, want tenant-2 with 58", updates[1])
+	}
+
+	unsubscribe()
+	callWithLimits(t, cl, server.URL, "tenant-1", map[string]string{minuteLimitRemainingHeader: "57"})
+	if len(updates) != 2 {
+		t.Errorf("updates after unsubscribe = %d, want 2", len(updates))
+	}
+}
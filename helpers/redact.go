@@ -0,0 +1,132 @@
+package helpers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// RedactedHeaders are the headers hidden by RedactHeaders
+var RedactedHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+	xeroTenantIDHeader,
+}
+
+// RedactedFields are the JSON fields hidden by RedactBody, the match is case
+// insensitive. Add to it the fields that hold personal data on your
+// organisation
+var RedactedFields = []string{
+	"access_token",
+	"refresh_token",
+	"id_token",
+	"EmailAddress",
+	"FirstName",
+	"LastName",
+	"PhoneNumber",
+	"PhoneAreaCode",
+	"AddressLine1",
+	"AddressLine2",
+	"AddressLine3",
+	"AddressLine4",
+	"PostalCode",
+	"BankAccountDetails",
+	"BankAccountNumber",
+	"TaxNumber",
+	"SkypeUserName",
+}
+
+// RedactedQueryParams are the query parameters hidden by RedactURL, the match
+// is case insensitive. The where filter holds the values searched for, such as
+// names and email addresses
+var RedactedQueryParams = []string{
+	"where",
+	"searchTerm",
+	"IDs",
+	"ContactIDs",
+	"InvoiceNumbers",
+}
+
+// RedactURL function will return the given URL as a string with the values of
+// RedactedQueryParams hidden
+func RedactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	if u.RawQuery == "" {
+		return u.String()
+	}
+	query := u.Query()
+	for name, values := range query {
+		for _, param := range RedactedQueryParams {
+			if strings.EqualFold(name, param) {
+				for i := range values {
+					values[i] = redacted
+				}
+			}
+		}
+	}
+	clean := *u
+	clean.RawQuery = query.Encode()
+	return clean.String()
+}
+
+// RedactHeaders function will return a copy of the given headers with the
+// credentials and the tenant hidden
+func RedactHeaders(h http.Header) http.Header {
+	clean := h.Clone()
+	for _, name := range RedactedHeaders {
+		values := clean[http.CanonicalHeaderKey(name)]
+		for i, value := range values {
+			if scheme := strings.SplitN(value, " ", 2); len(scheme) == 2 {
+				values[i] = scheme[0] + " " + redacted
+				continue
+			}
+			values[i] = redacted
+		}
+	}
+	return clean
+}
+
+// RedactBody function will return a copy of the given JSON body with the
+// values of RedactedFields hidden, bodies that are not JSON are fully hidden
+func RedactBody(body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return []byte(redacted)
+	}
+	fields := make(map[string]bool, len(RedactedFields))
+	for _, field := range RedactedFields {
+		fields[strings.ToLower(field)] = true
+	}
+	buf, err := json.Marshal(redactValue(decoded, fields))
+	if err != nil {
+		return []byte(redacted)
+	}
+	return buf
+}
+
+func redactValue(value interface{}, fields map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, inner := range v {
+			if fields[strings.ToLower(key)] {
+				v[key] = redacted
+				continue
+			}
+			v[key] = redactValue(inner, fields)
+		}
+	case []interface{}:
+		for i, inner := range v {
+			v[i] = redactValue(inner, fields)
+		}
+	}
+	return value
+}
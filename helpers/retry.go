@@ -0,0 +1,221 @@
+package helpers
+
+import (
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	retryAfterHeader       = "Retry-After"
+	rateLimitProblemHeader = "X-Rate-Limit-Problem"
+
+	// maxDrainBytes is the amount of a discarded response body we read before
+	// closing it so the connection can be reused
+	maxDrainBytes = 4 << 10
+)
+
+// RetryPolicy keeps the configuration used by RetryTransport to decide when
+// and how long to wait before sending a request again
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts for a request, counting
+	// the first one. Values lower than 1 mean a single attempt
+	MaxAttempts int
+
+	// MaxElapsed is the maximum time spent on a request including all the
+	// waits between attempts, zero means no limit
+	MaxElapsed time.Duration
+
+	// BaseDelay is the delay used for the first retry, it doubles on each
+	// attempt until it reaches MaxDelay. A random jitter is applied to it
+	BaseDelay time.Duration
+
+	// MaxDelay caps the exponential backoff, it doesn't apply to the delays
+	// asked by Xero with the Retry-After header
+	MaxDelay time.Duration
+
+	// RetryNonIdempotent allows PUT and POST requests to be retried after a
+	// network error, in that case Xero could have processed the first attempt
+	// and the write could be duplicated. Requests sent with an Idempotency-Key
+	// header are always retried as Xero won't process them twice
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy will return the policy recommended for the Xero API
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		MaxElapsed:  2 * time.Minute,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+	}
+}
+
+// RetryTransport is a http.RoundTripper that will send again the requests
+// rejected with a 429 or 503 status code and the ones that failed with a
+// network error
+type RetryTransport struct {
+	T      http.RoundTripper
+	Policy RetryPolicy
+
+	// Observer, when set, is told about each retry
+	Observer Observer
+}
+
+// NewRetryTransport will build a new RetryTransport on top of the given
+// transport, if it's nil http.DefaultTransport is used
+func NewRetryTransport(t http.RoundTripper, policy RetryPolicy) *RetryTransport {
+	if t == nil {
+		t = http.DefaultTransport
+	}
+	return &RetryTransport{
+		T:      t,
+		Policy: policy,
+	}
+}
+
+// RoundTrip method will send the request until it succeeds, it can't be
+// retried or the policy budget is spent
+func (rt *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	meta := responseMetaFromContext(ctx)
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		attemptReq, err := rewindRequest(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+		if meta != nil {
+			meta.Attempts = attempt
+		}
+		response, err := rt.T.RoundTrip(attemptReq)
+		if !rt.shouldRetry(req, response, err, attempt) {
+			return response, err
+		}
+		if err != nil && meta != nil && req.Header.Get(idempotencyKeyHeader) != "" {
+			meta.PossibleReplay = true
+		}
+
+		delay := rt.Policy.backoff(attempt)
+		if response != nil {
+			if retryAfter, ok := parseRetryAfter(response.Header.Get(retryAfterHeader)); ok {
+				delay = retryAfter
+			}
+		}
+		if rt.Policy.MaxElapsed > 0 && time.Since(start)+delay > rt.Policy.MaxElapsed {
+			return response, err
+		}
+		if rt.Observer != nil {
+			info := RetryInfo{
+				RequestInfo: newRequestInfo(req),
+				Attempt:     attempt,
+				Delay:       delay,
+				Err:         err,
+			}
+			if response != nil {
+				info.StatusCode = response.StatusCode
+			}
+			rt.Observer.OnRetry(ctx, info)
+		}
+		if response != nil {
+			drainBody(response.Body)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// shouldRetry decides if the result of an attempt can be retried
+func (rt *RetryTransport) shouldRetry(req *http.Request, response *http.Response, err error, attempt int) bool {
+	if attempt >= rt.Policy.MaxAttempts || req.Context().Err() != nil {
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		// The body was consumed by the first attempt and we can't send it again
+		return false
+	}
+	if err != nil {
+		return isIdempotent(req.Method) || rt.Policy.RetryNonIdempotent ||
+			req.Header.Get(idempotencyKeyHeader) != ""
+	}
+	// Xero rejects these before processing the request, so even writes are
+	// safe to send again
+	return response.StatusCode == http.StatusTooManyRequests ||
+		response.StatusCode == http.StatusServiceUnavailable
+}
+
+// backoff will return the exponential delay with full jitter for the given
+// attempt
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(delay)))
+}
+
+// rewindRequest will return the request to send on the given attempt with a
+// fresh copy of its body
+func rewindRequest(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 1 || req.GetBody == nil {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	r.Body = body
+	return r, nil
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter will read the Retry-After header that can be given in
+// seconds or as an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	delay := time.Until(date)
+	if delay < 0 {
+		delay = 0
+	}
+	return delay, true
+}
+
+func drainBody(body io.ReadCloser) {
+	io.Copy(ioutil.Discard, io.LimitReader(body, maxDrainBytes))
+	body.Close()
+}
@@ -0,0 +1,207 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedServer answers each request with the next status of the script, the
+// last one is repeated. The bodies received are kept
+type scriptedServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	header   http.Header
+	bodies   []string
+}
+
+func newScriptedServer(header http.Header, statuses ...int) *scriptedServer {
+	s := &scriptedServer{statuses: statuses, header: header}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		s.mu.Lock()
+		index := len(s.bodies)
+		s.bodies = append(s.bodies, string(body))
+		s.mu.Unlock()
+		if index >= len(s.statuses) {
+			index = len(s.statuses) - 1
+		}
+		status := s.statuses[index]
+		if status != http.StatusOK {
+			for key, values := range s.header {
+				w.Header()[key] = values
+			}
+		}
+		w.WriteHeader(status)
+	}))
+	return s
+}
+
+func (s *scriptedServer) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.bodies...)
+}
+
+func testPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    5 * time.Millisecond,
+	}
+}
+
+func TestRetryTransportStatuses(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		header   http.Header
+		status   int
+		attempts int
+		minWait  time.Duration
+	}{
+		{
+			name:     "429 with Retry-After",
+			statuses: []int{http.StatusTooManyRequests, http.StatusOK},
+			header:   http.Header{retryAfterHeader: {"1"}},
+			status:   http.StatusOK,
+			attempts: 2,
+			minWait:  time.Second,
+		},
+		{
+			name:     "503",
+			statuses: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			status:   http.StatusOK,
+			attempts: 3,
+		},
+		{
+			name:     "attempts spent",
+			statuses: []int{http.StatusServiceUnavailable},
+			status:   http.StatusServiceUnavailable,
+			attempts: 3,
+		},
+		{
+			name:     "not retryable",
+			statuses: []int{http.StatusBadRequest, http.StatusOK},
+			status:   http.StatusBadRequest,
+			attempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newScriptedServer(tt.header, tt.statuses...)
+			defer server.Close()
+			cl := &http.Client{Transport: NewRetryTransport(nil, testPolicy())}
+
+			start := time.Now()
+			resp, err := cl.Get(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if got := len(server.received()); got != tt.attempts {
+				t.Errorf("attempts = %d, want %d", got, tt.attempts)
+			}
+			if elapsed := time.Since(start); elapsed < tt.minWait {
+				t.Errorf("elapsed = %v, want at least %v", elapsed, tt.minWait)
+			}
+		})
+	}
+}
+
+func TestRetryTransportRewindsBody(t *testing.T) {
+	server := newScriptedServer(nil, http.StatusServiceUnavailable, http.StatusOK)
+	defer server.Close()
+	cl := &http.Client{Transport: NewRetryTransport(nil, testPolicy())}
+
+	body := `{"Contacts":[{"Name":"ACME"}]}`
+	req, err := http.NewRequest(http.MethodPut, server.URL, bytes.NewReader([]byte(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := cl.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	received := server.received()
+	if len(received) != 2 {
+		t.Fatalf("attempts = %d, want 2", len(received))
+	}
+	for i, got := range received {
+		if got != body {
+			t.Errorf("attempt %d sent %q, want %q", i+1, got, body)
+		}
+	}
+}
+
+func TestRetryTransportMaxElapsed(t *testing.T) {
+	server := newScriptedServer(http.Header{retryAfterHeader: {"30"}}, http.StatusTooManyRequests)
+	defer server.Close()
+	policy := testPolicy()
+	policy.MaxElapsed = time.Second
+	cl := &http.Client{Transport: NewRetryTransport(nil, policy)}
+
+	start := time.Now()
+	resp, err := cl.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusTooManyRequests)
+	}
+	if got := len(server.received()); got != 1 {
+		t.Errorf("attempts = %d, want 1", got)
+	}
+	if elapsed := time.Since(start); elapsed > policy.MaxElapsed {
+		t.Errorf("elapsed = %v, want less than %v", elapsed, policy.MaxElapsed)
+	}
+}
+
+func TestRetryTransportNetworkErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		key      string
+		attempts int
+	}{
+		{name: "GET", method: http.MethodGet, attempts: 3},
+		{name: "PUT", method: http.MethodPut, attempts: 1},
+		{name: "PUT with idempotency key", method: http.MethodPut, key: "key-1", attempts: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			transport := NewRetryTransport(RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+				attempts++
+				return nil, errors.New("connection reset")
+			}), testPolicy())
+
+			req, err := http.NewRequest(tt.method, "http://xero.test/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.key != "" {
+				req.Header.Set(idempotencyKeyHeader, tt.key)
+			}
+			if _, err := transport.RoundTrip(req); err == nil {
+				t.Fatal("expected an error")
+			}
+			if attempts != tt.attempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.attempts)
+			}
+		})
+	}
+}
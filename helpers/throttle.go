@@ -0,0 +1,258 @@
+package helpers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ThrottleLimits keeps the limits enforced by ThrottleTransport, a zero value
+// disables that limit
+type ThrottleLimits struct {
+	// PerMinute is the number of calls allowed per tenant and minute
+	PerMinute int
+
+	// Concurrent is the number of calls allowed in flight per tenant
+	Concurrent int
+
+	// AppPerMinute is the number of calls allowed per minute for the whole
+	// app, regardless of the tenant
+	AppPerMinute int
+}
+
+// DefaultThrottleLimits will return the limits documented by Xero
+func DefaultThrottleLimits() ThrottleLimits {
+	return ThrottleLimits{
+		PerMinute:    60,
+		Concurrent:   5,
+		AppPerMinute: 10000,
+	}
+}
+
+// ThrottleTransport is a http.RoundTripper that holds back the requests that
+// would exceed the Xero rate limits, instead of letting Xero reject them with
+// a 429. The tenant is taken from the xero-tenant-id header of the request,
+// requests without it only count for the app limit. The state of a tenant is
+// dropped once it has been idle for a minute, so long running apps don't keep
+// every tenant they ever called
+type ThrottleTransport struct {
+	T      http.RoundTripper
+	Limits ThrottleLimits
+
+	mu      sync.Mutex
+	app     *minuteWindow
+	tenants map[string]*tenantThrottle
+	swept   time.Time
+}
+
+type tenantThrottle struct {
+	window *minuteWindow
+	slots  chan struct{}
+
+	// active is the number of requests using the throttle, guarded by the
+	// mutex of the transport
+	active int
+}
+
+// NewThrottleTransport will build a new ThrottleTransport on top of the given
+// transport, if it's nil http.DefaultTransport is used
+func NewThrottleTransport(t http.RoundTripper, limits ThrottleLimits) *ThrottleTransport {
+	if t == nil {
+		t = http.DefaultTransport
+	}
+	return &ThrottleTransport{
+		T:      t,
+		Limits: limits,
+	}
+}
+
+// RoundTrip method will wait until the request is within the minute limits,
+// then for a free slot of the tenant, and then send it. The wait ends early
+// when the request context is done
+func (tt *ThrottleTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	tenantID := req.Header.Get(xeroTenantIDHeader)
+	app, tenant := tt.acquire(tenantID)
+	if tenant != nil {
+		defer tt.done(tenant)
+	}
+
+	windows := []*minuteWindow{app}
+	if tenant != nil {
+		windows = append(windows, tenant.window)
+	}
+	if err := waitWindows(ctx, windows...); err != nil {
+		closeBody(req)
+		return nil, err
+	}
+	if tenant != nil && tenant.slots != nil {
+		select {
+		case tenant.slots <- struct{}{}:
+			defer func() { <-tenant.slots }()
+		case <-ctx.Done():
+			closeBody(req)
+			return nil, ctx.Err()
+		}
+	}
+	return tt.T.RoundTrip(req)
+}
+
+// acquire will return the app throttle and the one of the given tenant,
+// building them on first use. The tenant throttle is kept until done is
+// called for it
+func (tt *ThrottleTransport) acquire(tenantID string) (*minuteWindow, *tenantThrottle) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	if tt.app == nil && tt.Limits.AppPerMinute > 0 {
+		tt.app = newMinuteWindow(tt.Limits.AppPerMinute)
+	}
+	if tenantID == "" {
+		return tt.app, nil
+	}
+	tt.sweep()
+	if tt.tenants == nil {
+		tt.tenants = make(map[string]*tenantThrottle)
+	}
+	tenant, ok := tt.tenants[tenantID]
+	if !ok {
+		tenant = &tenantThrottle{}
+		if tt.Limits.PerMinute > 0 {
+			tenant.window = newMinuteWindow(tt.Limits.PerMinute)
+		}
+		if tt.Limits.Concurrent > 0 {
+			tenant.slots = make(chan struct{}, tt.Limits.Concurrent)
+		}
+		tt.tenants[tenantID] = tenant
+	}
+	tenant.active++
+	return tt.app, tenant
+}
+
+// done will mark the end of a request using the tenant throttle
+func (tt *ThrottleTransport) done(tenant *tenantThrottle) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	tenant.active--
+}
+
+// sweep will drop the tenants without requests in flight and without calls
+// in the last minute, their throttle would let any request through. It runs
+// at most once a minute and must be called with the mutex held
+func (tt *ThrottleTransport) sweep() {
+	now := time.Now()
+	if now.Sub(tt.swept) < time.Minute {
+		return
+	}
+	tt.swept = now
+	for tenantID, tenant := range tt.tenants {
+		if tenant.active == 0 && tenant.window.idle(now) {
+			delete(tt.tenants, tenantID)
+		}
+	}
+}
+
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// minuteWindow allows a number of calls in any window of a minute, as Xero
+// counts them. It keeps the start time of the calls of the last minute and
+// the ones booked ahead, a new call waits until the limit-th latest of them
+// is a minute old
+type minuteWindow struct {
+	mu    sync.Mutex
+	limit int
+	calls []time.Time
+}
+
+func newMinuteWindow(perMinute int) *minuteWindow {
+	return &minuteWindow{
+		limit: perMinute,
+		calls: make([]time.Time, 0, perMinute),
+	}
+}
+
+// reserve will book the start time of a call and return it, the times booked
+// never decrease
+func (w *minuteWindow) reserve() time.Time {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	start := time.Now()
+	old := 0
+	for old < len(w.calls) && start.Sub(w.calls[old]) >= time.Minute {
+		old++
+	}
+	w.calls = w.calls[old:]
+	if len(w.calls) >= w.limit {
+		if next := w.calls[len(w.calls)-w.limit].Add(time.Minute); next.After(start) {
+			start = next
+		}
+	}
+	if len(w.calls) > 0 && w.calls[len(w.calls)-1].After(start) {
+		start = w.calls[len(w.calls)-1]
+	}
+	w.calls = append(w.calls, start)
+	return start
+}
+
+// release will drop a start time booked but not used
+func (w *minuteWindow) release(start time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i := len(w.calls) - 1; i >= 0; i-- {
+		if w.calls[i].Equal(start) {
+			w.calls = append(w.calls[:i], w.calls[i+1:]...)
+			return
+		}
+	}
+}
+
+// idle method will tell if the window has no call in the minute before now,
+// a nil window is always idle
+func (w *minuteWindow) idle(now time.Time) bool {
+	if w == nil {
+		return true
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.calls) == 0 || now.Sub(w.calls[len(w.calls)-1]) >= time.Minute
+}
+
+// waitWindows function will book a call in each of the windows and block
+// until all of them allow it. When the context is done first every booking is
+// released, so a cancelled request doesn't count for any limit. The nil
+// windows are skipped
+func waitWindows(ctx context.Context, windows ...*minuteWindow) error {
+	var starts []time.Time
+	var latest time.Time
+	for _, w := range windows {
+		var start time.Time
+		if w != nil {
+			start = w.reserve()
+			if start.After(latest) {
+				latest = start
+			}
+		}
+		starts = append(starts, start)
+	}
+	delay := time.Until(latest)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		for i, w := range windows {
+			if w != nil {
+				w.release(starts[i])
+			}
+		}
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,159 @@
+package helpers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestThrottleTransportPerMinute(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer server.Close()
+	cl := &http.Client{Transport: NewThrottleTransport(nil, ThrottleLimits{PerMinute: 6})}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	var held int
+	for i := 0; i < 8; i++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(xeroTenantIDHeader, "tenant-1")
+		resp, err := cl.Do(req)
+		if err != nil {
+			held++
+			continue
+		}
+		resp.Body.Close()
+	}
+	if calls != 6 || held != 2 {
+		t.Errorf("calls = %d, held = %d, want 6 and 2", calls, held)
+	}
+}
+
+func TestThrottleTransportTenants(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	cl := &http.Client{Transport: NewThrottleTransport(nil, ThrottleLimits{PerMinute: 1})}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	for _, tenantID := range []string{"tenant-1", "tenant-2"} {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(xeroTenantIDHeader, tenantID)
+		resp, err := cl.Do(req)
+		if err != nil {
+			t.Fatalf("%s: %v", tenantID, err)
+		}
+		resp.Body.Close()
+	}
+}
+
+func TestThrottleTransportConcurrent(t *testing.T) {
+	var inFlight, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&peak)
+			if current <= max || atomic.CompareAndSwapInt32(&peak, max, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+	cl := &http.Client{Transport: NewThrottleTransport(nil, ThrottleLimits{Concurrent: 2})}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			req.Header.Set(xeroTenantIDHeader, "tenant-1")
+			resp, err := cl.Do(req)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+	if peak > 2 {
+		t.Errorf("peak concurrency = %d, want at most 2", peak)
+	}
+}
+
+func TestThrottleTransportCancelReleases(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	transport := NewThrottleTransport(nil, ThrottleLimits{PerMinute: 2, Concurrent: 1, AppPerMinute: 1})
+	cl := &http.Client{Transport: transport}
+	send := func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(xeroTenantIDHeader, "tenant-1")
+		resp, err := cl.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+	if err := send(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second call waits for the app window without taking the slot
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- send(ctx) }()
+	time.Sleep(50 * time.Millisecond)
+	tenant := transport.tenants["tenant-1"]
+	if len(tenant.slots) != 0 {
+		t.Error("the slot is taken while waiting for the windows")
+	}
+	cancel()
+	if err := <-errc; err == nil {
+		t.Fatal("the cancelled call was sent")
+	}
+	if calls := len(tenant.window.calls); calls != 1 {
+		t.Errorf("tenant window = %d calls, want the cancelled one released", calls)
+	}
+	if calls := len(transport.app.calls); calls != 1 {
+		t.Errorf("app window = %d calls, want the cancelled one released", calls)
+	}
+}
+
+func TestThrottleTransportSweep(t *testing.T) {
+	transport := NewThrottleTransport(nil, ThrottleLimits{PerMinute: 1, Concurrent: 1})
+	_, busy := transport.acquire("busy")
+	_, idle := transport.acquire("idle")
+	transport.done(idle)
+	_, recent := transport.acquire("recent")
+	recent.window.reserve()
+	transport.done(recent)
+
+	transport.swept = time.Now().Add(-2 * time.Minute)
+	transport.acquire("other")
+	for tenantID, want := range map[string]bool{"busy": true, "idle": false, "recent": true, "other": true} {
+		if _, ok := transport.tenants[tenantID]; ok != want {
+			t.Errorf("tenant %s kept = %v, want %v", tenantID, ok, want)
+		}
+	}
+	transport.done(busy)
+}
@@ -0,0 +1,40 @@
+package helpers
+
+import "testing"
+
+func TestDotNetJSONTimeToRFC3339(t *testing.T) {
+	tests := []struct {
+		name     string
+		jsonTime string
+		isUTC    bool
+		want     string
+	}{
+		{"offset in UTC", "/Date(1494201600000+1300)/", true, "2017-05-08T00:00:00Z"},
+		{"offset in local time", "/Date(1494201600000+1300)/", false, "2017-05-08T13:00:00"},
+		{"negative offset in UTC", "/Date(1494201600000-0500)/", true, "2017-05-08T00:00:00Z"},
+		{"negative offset in local time", "/Date(1494201600000-0530)/", false, "2017-05-07T18:30:00"},
+		{"no offset in UTC", "/Date(1518685950940)/", true, "2018-02-15T09:12:30Z"},
+		{"no offset in local time", "/Date(1518685950940)/", false, "2018-02-15T09:12:30"},
+		{"DateString", "2017-05-08T10:30:00", true, "2017-05-08T10:30:00Z"},
+		{"DateString in local time", "2017-05-08T10:30:00", false, "2017-05-08T10:30:00"},
+		{"date only", "2017-05-08", true, "2017-05-08T00:00:00Z"},
+		{"empty", "", true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DotNetJSONTimeToRFC3339(tt.jsonTime, tt.isUTC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("DotNetJSONTimeToRFC3339(%q, %v) = %q, want %q", tt.jsonTime, tt.isUTC, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDotNetJSONTimeToRFC3339Invalid(t *testing.T) {
+	if got, err := DotNetJSONTimeToRFC3339("/Date(abc)/", true); err == nil {
+		t.Errorf("DotNetJSONTimeToRFC3339 = %q, want an error", got)
+	}
+}
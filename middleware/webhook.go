@@ -7,6 +7,8 @@ import (
 	"encoding/base64"
 	"io/ioutil"
 	"net/http"
+
+	"github.com/quickaco/xerosdk/helpers"
 )
 
 const (
@@ -16,6 +18,13 @@ const (
 // WebhookAuthorizationMiddleware will check if the webhook request has the correct
 // signature
 func WebhookAuthorizationMiddleware(webhookSigningKey string) func(next http.Handler) http.Handler {
+	return WebhookAuthorizationMiddlewareWithObserver(webhookSigningKey, nil)
+}
+
+// WebhookAuthorizationMiddlewareWithObserver is the same as
+// WebhookAuthorizationMiddleware but the given observer is told about each
+// webhook request received
+func WebhookAuthorizationMiddlewareWithObserver(webhookSigningKey string, observer helpers.Observer) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			signatureVal := r.Header.Get(signatureHeader)
@@ -33,7 +42,11 @@ func WebhookAuthorizationMiddleware(webhookSigningKey string) func(next http.Han
 				return
 			}
 			expectedMAC := mac.Sum(nil)
-			if base64.StdEncoding.EncodeToString(expectedMAC) != signatureVal {
+			valid := base64.StdEncoding.EncodeToString(expectedMAC) == signatureVal
+			if observer != nil {
+				observer.OnWebhook(r.Context(), helpers.WebhookInfo{Valid: valid})
+			}
+			if !valid {
 				w.WriteHeader(http.StatusUnauthorized)
 				return
 			}
@@ -0,0 +1,216 @@
+package query
+
+// Invoice keeps the fields of the Invoices endpoint that can be used on a
+// query
+var Invoice = struct {
+	InvoiceID      GUIDField
+	InvoiceNumber  StringField
+	Type           StringField
+	Status         StringField
+	Reference      StringField
+	CurrencyCode   StringField
+	ContactID      GUIDField
+	ContactName    StringField
+	ContactNumber  StringField
+	Date           DateField
+	DueDate        DateField
+	UpdatedDateUTC DateField
+	SubTotal       NumberField
+	Total          NumberField
+	AmountDue      NumberField
+	AmountPaid     NumberField
+	SentToContact  BoolField
+	HasAttachments BoolField
+}{
+	InvoiceID:      NewGUIDField("InvoiceID"),
+	InvoiceNumber:  NewStringField("InvoiceNumber"),
+	Type:           NewStringField("Type"),
+	Status:         NewStringField("Status"),
+	Reference:      NewStringField("Reference"),
+	CurrencyCode:   NewStringField("CurrencyCode"),
+	ContactID:      NewGUIDField("Contact.ContactID"),
+	ContactName:    NewStringField("Contact.Name"),
+	ContactNumber:  NewStringField("Contact.ContactNumber"),
+	Date:           NewDateField("Date"),
+	DueDate:        NewDateField("DueDate"),
+	UpdatedDateUTC: NewDateField("UpdatedDateUTC"),
+	SubTotal:       NewNumberField("SubTotal"),
+	Total:          NewNumberField("Total"),
+	AmountDue:      NewNumberField("AmountDue"),
+	AmountPaid:     NewNumberField("AmountPaid"),
+	SentToContact:  NewBoolField("SentToContact"),
+	HasAttachments: NewBoolField("HasAttachments"),
+}
+
+// Contact keeps the fields of the Contacts endpoint that can be used on a
+// query
+var Contact = struct {
+	ContactID       GUIDField
+	ContactNumber   StringField
+	AccountNumber   StringField
+	ContactStatus   StringField
+	Name            StringField
+	FirstName       StringField
+	LastName        StringField
+	EmailAddress    StringField
+	TaxNumber       StringField
+	DefaultCurrency StringField
+	UpdatedDateUTC  DateField
+	IsSupplier      BoolField
+	IsCustomer      BoolField
+	HasAttachments  BoolField
+}{
+	ContactID:       NewGUIDField("ContactID"),
+	ContactNumber:   NewStringField("ContactNumber"),
+	AccountNumber:   NewStringField("AccountNumber"),
+	ContactStatus:   NewStringField("ContactStatus"),
+	Name:            NewStringField("Name"),
+	FirstName:       NewStringField("FirstName"),
+	LastName:        NewStringField("LastName"),
+	EmailAddress:    NewStringField("EmailAddress"),
+	TaxNumber:       NewStringField("TaxNumber"),
+	DefaultCurrency: NewStringField("DefaultCurrency"),
+	UpdatedDateUTC:  NewDateField("UpdatedDateUTC"),
+	IsSupplier:      NewBoolField("IsSupplier"),
+	IsCustomer:      NewBoolField("IsCustomer"),
+	HasAttachments:  NewBoolField("HasAttachments"),
+}
+
+// Account keeps the fields of the Accounts endpoint that can be used on a
+// query
+var Account = struct {
+	AccountID               GUIDField
+	Code                    StringField
+	Name                    StringField
+	Type                    StringField
+	Status                  StringField
+	Class                   StringField
+	TaxType                 StringField
+	CurrencyCode            StringField
+	BankAccountNumber       StringField
+	BankAccountType         StringField
+	SystemAccount           StringField
+	ReportingCode           StringField
+	UpdatedDateUTC          DateField
+	EnablePaymentsToAccount BoolField
+	ShowInExpenseClaims     BoolField
+	HasAttachments          BoolField
+}{
+	AccountID:               NewGUIDField("AccountID"),
+	Code:                    NewStringField("Code"),
+	Name:                    NewStringField("Name"),
+	Type:                    NewStringField("Type"),
+	Status:                  NewStringField("Status"),
+	Class:                   NewStringField("Class"),
+	TaxType:                 NewStringField("TaxType"),
+	CurrencyCode:            NewStringField("CurrencyCode"),
+	BankAccountNumber:       NewStringField("BankAccountNumber"),
+	BankAccountType:         NewStringField("BankAccountType"),
+	SystemAccount:           NewStringField("SystemAccount"),
+	ReportingCode:           NewStringField("ReportingCode"),
+	UpdatedDateUTC:          NewDateField("UpdatedDateUTC"),
+	EnablePaymentsToAccount: NewBoolField("EnablePaymentsToAccount"),
+	ShowInExpenseClaims:     NewBoolField("ShowInExpenseClaims"),
+	HasAttachments:          NewBoolField("HasAttachments"),
+}
+
+// BankTransaction keeps the fields of the BankTransactions endpoint that can
+// be used on a query
+var BankTransaction = struct {
+	BankTransactionID GUIDField
+	Type              StringField
+	Status            StringField
+	Reference         StringField
+	CurrencyCode      StringField
+	ContactID         GUIDField
+	ContactName       StringField
+	BankAccountID     GUIDField
+	BankAccountCode   StringField
+	Date              DateField
+	UpdatedDateUTC    DateField
+	SubTotal          NumberField
+	Total             NumberField
+	IsReconciled      BoolField
+	HasAttachments    BoolField
+}{
+	BankTransactionID: NewGUIDField("BankTransactionID"),
+	Type:              NewStringField("Type"),
+	Status:            NewStringField("Status"),
+	Reference:         NewStringField("Reference"),
+	CurrencyCode:      NewStringField("CurrencyCode"),
+	ContactID:         NewGUIDField("Contact.ContactID"),
+	ContactName:       NewStringField("Contact.Name"),
+	BankAccountID:     NewGUIDField("BankAccount.AccountID"),
+	BankAccountCode:   NewStringField("BankAccount.Code"),
+	Date:              NewDateField("Date"),
+	UpdatedDateUTC:    NewDateField("UpdatedDateUTC"),
+	SubTotal:          NewNumberField("SubTotal"),
+	Total:             NewNumberField("Total"),
+	IsReconciled:      NewBoolField("IsReconciled"),
+	HasAttachments:    NewBoolField("HasAttachments"),
+}
+
+// CreditNote keeps the fields of the CreditNotes endpoint that can be used on
+// a query
+var CreditNote = struct {
+	CreditNoteID     GUIDField
+	CreditNoteNumber StringField
+	Type             StringField
+	Status           StringField
+	Reference        StringField
+	CurrencyCode     StringField
+	ContactID        GUIDField
+	ContactName      StringField
+	Date             DateField
+	FullyPaidOnDate  DateField
+	UpdatedDateUTC   DateField
+	SubTotal         NumberField
+	Total            NumberField
+	RemainingCredit  NumberField
+	SentToContact    BoolField
+	HasAttachments   BoolField
+}{
+	CreditNoteID:     NewGUIDField("CreditNoteID"),
+	CreditNoteNumber: NewStringField("CreditNoteNumber"),
+	Type:             NewStringField("Type"),
+	Status:           NewStringField("Status"),
+	Reference:        NewStringField("Reference"),
+	CurrencyCode:     NewStringField("CurrencyCode"),
+	ContactID:        NewGUIDField("Contact.ContactID"),
+	ContactName:      NewStringField("Contact.Name"),
+	Date:             NewDateField("Date"),
+	FullyPaidOnDate:  NewDateField("FullyPaidOnDate"),
+	UpdatedDateUTC:   NewDateField("UpdatedDateUTC"),
+	SubTotal:         NewNumberField("SubTotal"),
+	Total:            NewNumberField("Total"),
+	RemainingCredit:  NewNumberField("RemainingCredit"),
+	SentToContact:    NewBoolField("SentToContact"),
+	HasAttachments:   NewBoolField("HasAttachments"),
+}
+
+// Item keeps the fields of the Items endpoint that can be used on a query
+var Item = struct {
+	ItemID               GUIDField
+	Code                 StringField
+	Name                 StringField
+	Description          StringField
+	PurchaseDescription  StringField
+	UpdatedDateUTC       DateField
+	QuantityOnHand       NumberField
+	TotalCostPool        NumberField
+	IsSold               BoolField
+	IsPurchased          BoolField
+	IsTrackedAsInventory BoolField
+}{
+	ItemID:               NewGUIDField("ItemID"),
+	Code:                 NewStringField("Code"),
+	Name:                 NewStringField("Name"),
+	Description:          NewStringField("Description"),
+	PurchaseDescription:  NewStringField("PurchaseDescription"),
+	UpdatedDateUTC:       NewDateField("UpdatedDateUTC"),
+	QuantityOnHand:       NewNumberField("QuantityOnHand"),
+	TotalCostPool:        NewNumberField("TotalCostPool"),
+	IsSold:               NewBoolField("IsSold"),
+	IsPurchased:          NewBoolField("IsPurchased"),
+	IsTrackedAsInventory: NewBoolField("IsTrackedAsInventory"),
+}
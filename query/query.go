@@ -0,0 +1,384 @@
+// Package query builds the where and order expressions accepted by the Xero
+// accounting API, the output of a Query can be given to any Find function that
+// takes query parameters
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/decimal"
+)
+
+const (
+	whereParameter = "where"
+	orderParameter = "order"
+)
+
+// stringEscaper doubles the double quotes, as the Dynamic LINQ parser used by
+// Xero expects. The backslash has no special meaning there and is kept as is
+var stringEscaper = strings.NewReplacer(`"`, `""`)
+
+// Expression is a filter of a where clause
+type Expression struct {
+	value string
+
+	// compound is true for the expressions built with And and Or, they are
+	// wrapped in parentheses when nested
+	compound bool
+}
+
+// String method will return the expression as expected by Xero
+func (e Expression) String() string {
+	return e.value
+}
+
+// IsZero method will return true for the empty expression
+func (e Expression) IsZero() bool {
+	return e.value == ""
+}
+
+// And function will join the given expressions with the AND operator, empty
+// expressions are ignored
+func And(expressions ...Expression) Expression {
+	return join("AND", expressions)
+}
+
+// Or function will join the given expressions with the OR operator, empty
+// expressions are ignored
+func Or(expressions ...Expression) Expression {
+	return join("OR", expressions)
+}
+
+func join(operator string, expressions []Expression) Expression {
+	var kept []Expression
+	for _, e := range expressions {
+		if !e.IsZero() {
+			kept = append(kept, e)
+		}
+	}
+	switch len(kept) {
+	case 0:
+		return Expression{}
+	case 1:
+		return kept[0]
+	}
+	parts := make([]string, 0, len(kept))
+	for _, e := range kept {
+		if e.compound {
+			parts = append(parts, "("+e.value+")")
+			continue
+		}
+		parts = append(parts, e.value)
+	}
+	return Expression{value: strings.Join(parts, " "+operator+" "), compound: true}
+}
+
+// Raw function will wrap an expression written by hand, it's not escaped
+func Raw(expression string) Expression {
+	return Expression{value: expression}
+}
+
+// String function will return the given value as a Xero string literal, the
+// double quotes it contains are doubled
+func String(value string) string {
+	return `"` + stringEscaper.Replace(value) + `"`
+}
+
+// GUID function will return the given value as a Xero GUID literal
+func GUID(id uuid.UUID) string {
+	return fmt.Sprintf("Guid(%q)", id.String())
+}
+
+// DateTime function will return the given time as a Xero DateTime literal,
+// the time of the day is only added when it's not midnight
+func DateTime(t time.Time) string {
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		return fmt.Sprintf("DateTime(%04d,%02d,%02d)", t.Year(), t.Month(), t.Day())
+	}
+	return fmt.Sprintf("DateTime(%04d,%02d,%02d,%02d,%02d,%02d)",
+		t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second())
+}
+
+func compare(field string, operator string, literal string) Expression {
+	return Expression{value: field + operator + literal}
+}
+
+// Field is the common part of all the typed fields, it allows to sort by it
+type Field struct {
+	name string
+}
+
+// Name method will return the name of the field as expected by Xero
+func (f Field) Name() string {
+	return f.name
+}
+
+// Asc method will sort by the field in ascending order
+func (f Field) Asc() Order {
+	return Order{field: f.name}
+}
+
+// Desc method will sort by the field in descending order
+func (f Field) Desc() Order {
+	return Order{field: f.name, desc: true}
+}
+
+// StringField is a field that holds text
+type StringField struct {
+	Field
+}
+
+// NewStringField function will build a StringField with the given name
+func NewStringField(name string) StringField {
+	return StringField{Field{name}}
+}
+
+// Eq method will match the elements with the given value
+func (f StringField) Eq(value string) Expression {
+	return compare(f.name, "==", String(value))
+}
+
+// Ne method will match the elements without the given value
+func (f StringField) Ne(value string) Expression {
+	return compare(f.name, "!=", String(value))
+}
+
+// Contains method will match the elements that contain the given value
+func (f StringField) Contains(value string) Expression {
+	return Expression{value: f.name + ".Contains(" + String(value) + ")"}
+}
+
+// StartsWith method will match the elements that start with the given value
+func (f StringField) StartsWith(value string) Expression {
+	return Expression{value: f.name + ".StartsWith(" + String(value) + ")"}
+}
+
+// EndsWith method will match the elements that end with the given value
+func (f StringField) EndsWith(value string) Expression {
+	return Expression{value: f.name + ".EndsWith(" + String(value) + ")"}
+}
+
+// In method will match the elements with any of the given values
+func (f StringField) In(values ...string) Expression {
+	expressions := make([]Expression, 0, len(values))
+	for _, value := range values {
+		expressions = append(expressions, f.Eq(value))
+	}
+	return Or(expressions...)
+}
+
+// GUIDField is a field that holds a Xero identifier
+type GUIDField struct {
+	Field
+}
+
+// NewGUIDField function will build a GUIDField with the given name
+func NewGUIDField(name string) GUIDField {
+	return GUIDField{Field{name}}
+}
+
+// Eq method will match the elements with the given identifier
+func (f GUIDField) Eq(id uuid.UUID) Expression {
+	return compare(f.name, "==", GUID(id))
+}
+
+// Ne method will match the elements without the given identifier
+func (f GUIDField) Ne(id uuid.UUID) Expression {
+	return compare(f.name, "!=", GUID(id))
+}
+
+// DateField is a field that holds a date
+type DateField struct {
+	Field
+}
+
+// NewDateField function will build a DateField with the given name
+func NewDateField(name string) DateField {
+	return DateField{Field{name}}
+}
+
+// Eq method will match the elements with the given date
+func (f DateField) Eq(t time.Time) Expression {
+	return compare(f.name, "==", DateTime(t))
+}
+
+// Ne method will match the elements without the given date
+func (f DateField) Ne(t time.Time) Expression {
+	return compare(f.name, "!=", DateTime(t))
+}
+
+// Gt method will match the elements after the given date
+func (f DateField) Gt(t time.Time) Expression {
+	return compare(f.name, ">", DateTime(t))
+}
+
+// Ge method will match the elements on or after the given date
+func (f DateField) Ge(t time.Time) Expression {
+	return compare(f.name, ">=", DateTime(t))
+}
+
+// Lt method will match the elements before the given date
+func (f DateField) Lt(t time.Time) Expression {
+	return compare(f.name, "<", DateTime(t))
+}
+
+// Le method will match the elements on or before the given date
+func (f DateField) Le(t time.Time) Expression {
+	return compare(f.name, "<=", DateTime(t))
+}
+
+// Between method will match the elements between the given dates, both
+// included
+func (f DateField) Between(from time.Time, to time.Time) Expression {
+	return And(f.Ge(from), f.Le(to))
+}
+
+// NumberField is a field that holds a number, such as an amount, compared
+// with a decimal.Decimal so the literal is exact
+type NumberField struct {
+	Field
+}
+
+// NewNumberField function will build a NumberField with the given name
+func NewNumberField(name string) NumberField {
+	return NumberField{Field{name}}
+}
+
+func number(value decimal.Decimal) string {
+	return value.String()
+}
+
+// Eq method will match the elements with the given value
+func (f NumberField) Eq(value decimal.Decimal) Expression {
+	return compare(f.name, "==", number(value))
+}
+
+// Ne method will match the elements without the given value
+func (f NumberField) Ne(value decimal.Decimal) Expression {
+	return compare(f.name, "!=", number(value))
+}
+
+// Gt method will match the elements greater than the given value
+func (f NumberField) Gt(value decimal.Decimal) Expression {
+	return compare(f.name, ">", number(value))
+}
+
+// Ge method will match the elements greater than or equal to the given value
+func (f NumberField) Ge(value decimal.Decimal) Expression {
+	return compare(f.name, ">=", number(value))
+}
+
+// Lt method will match the elements lower than the given value
+func (f NumberField) Lt(value decimal.Decimal) Expression {
+	return compare(f.name, "<", number(value))
+}
+
+// Le method will match the elements lower than or equal to the given value
+func (f NumberField) Le(value decimal.Decimal) Expression {
+	return compare(f.name, "<=", number(value))
+}
+
+// BoolField is a field that holds a boolean
+type BoolField struct {
+	Field
+}
+
+// NewBoolField function will build a BoolField with the given name
+func NewBoolField(name string) BoolField {
+	return BoolField{Field{name}}
+}
+
+// Eq method will match the elements with the given value
+func (f BoolField) Eq(value bool) Expression {
+	return compare(f.name, "==", strconv.FormatBool(value))
+}
+
+// IsTrue method will match the elements where the field is true
+func (f BoolField) IsTrue() Expression {
+	return f.Eq(true)
+}
+
+// IsFalse method will match the elements where the field is false
+func (f BoolField) IsFalse() Expression {
+	return f.Eq(false)
+}
+
+// Order is a sort criteria of the order clause
+type Order struct {
+	field string
+	desc  bool
+}
+
+// String method will return the sort criteria as expected by Xero
+func (o Order) String() string {
+	if o.desc {
+		return o.field + " DESC"
+	}
+	return o.field + " ASC"
+}
+
+// Query keeps the where and order clauses of a request
+type Query struct {
+	where Expression
+	order []Order
+}
+
+// Where function will start a new Query with the given expressions joined
+// with the AND operator
+func Where(expressions ...Expression) *Query {
+	return &Query{where: And(expressions...)}
+}
+
+// OrderBy function will start a new Query sorted by the given criteria
+func OrderBy(order ...Order) *Query {
+	return &Query{order: order}
+}
+
+// And method will add the given expressions to the where clause with the AND
+// operator
+func (q *Query) And(expressions ...Expression) *Query {
+	q.where = And(append([]Expression{q.where}, expressions...)...)
+	return q
+}
+
+// Or method will add the given expressions to the where clause with the OR
+// operator
+func (q *Query) Or(expressions ...Expression) *Query {
+	q.where = Or(append([]Expression{q.where}, expressions...)...)
+	return q
+}
+
+// OrderBy method will add the given sort criteria to the order clause
+func (q *Query) OrderBy(order ...Order) *Query {
+	q.order = append(q.order, order...)
+	return q
+}
+
+// Params method will return the query parameters for the Find functions
+func (q *Query) Params() map[string]string {
+	return q.Merge(nil)
+}
+
+// Merge method will return a copy of the given query parameters with the
+// where and order clauses of the query added
+func (q *Query) Merge(queryParameters map[string]string) map[string]string {
+	params := make(map[string]string, len(queryParameters)+2)
+	for key, value := range queryParameters {
+		params[key] = value
+	}
+	if !q.where.IsZero() {
+		params[whereParameter] = q.where.String()
+	}
+	if len(q.order) > 0 {
+		order := make([]string, 0, len(q.order))
+		for _, o := range q.order {
+			order = append(order, o.String())
+		}
+		params[orderParameter] = strings.Join(order, ",")
+	}
+	return params
+}
@@ -0,0 +1,73 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/decimal"
+)
+
+func TestExpressions(t *testing.T) {
+	id := uuid.Must(uuid.FromString("6f7b3f4e-4b5a-4a8e-9a52-1c8f0e0d5b3a"))
+	name := NewStringField("Name")
+	total := NewNumberField("Total")
+	date := NewDateField("Date")
+	tests := []struct {
+		name       string
+		expression Expression
+		want       string
+	}{
+		{"plain string", name.Eq("Acme"), `Name=="Acme"`},
+		{"quotes", name.Eq(`Say "hi"`), `Name=="Say ""hi"""`},
+		{"backslashes", name.Contains(`C:\path\`), `Name.Contains("C:\path\")`},
+		{"GUID", NewGUIDField("ContactID").Eq(id), `ContactID==Guid("6f7b3f4e-4b5a-4a8e-9a52-1c8f0e0d5b3a")`},
+		{"date", date.Ge(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)), `Date>=DateTime(2020,01,02)`},
+		{"date and time", date.Lt(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)), `Date<DateTime(2020,01,02,03,04,05)`},
+		{"decimal", total.Gt(decimal.MustParse("1234.50")), `Total>1234.5`},
+		{"negative decimal", total.Le(decimal.MustParse("-0.01")), `Total<=-0.01`},
+		{"bool", NewBoolField("IsSupplier").IsTrue(), `IsSupplier==true`},
+		{"in", name.In("A", "B"), `Name=="A" OR Name=="B"`},
+		{"empty and", And(Expression{}, name.Eq("A")), `Name=="A"`},
+		{
+			"and of ors",
+			And(Or(name.Eq("A"), name.Eq("B")), total.Gt(decimal.NewFromInt(10))),
+			`(Name=="A" OR Name=="B") AND Total>10`,
+		},
+		{
+			"or of ands",
+			Or(And(name.Eq("A"), total.Gt(decimal.NewFromInt(10))), And(name.Eq("B"), date.Between(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC)))),
+			`(Name=="A" AND Total>10) OR (Name=="B" AND (Date>=DateTime(2020,01,01) AND Date<=DateTime(2020,12,31)))`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.expression.String(); got != tt.want {
+				t.Errorf("expression = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryMerge(t *testing.T) {
+	q := Where(Invoice.Status.Eq("AUTHORISED")).
+		Or(Invoice.Status.Eq("PAID")).
+		OrderBy(Invoice.Date.Desc(), Invoice.InvoiceNumber.Asc())
+	params := q.Merge(map[string]string{"page": "2"})
+	want := map[string]string{
+		"page":  "2",
+		"where": `Status=="AUTHORISED" OR Status=="PAID"`,
+		"order": "Date DESC,InvoiceNumber ASC",
+	}
+	if len(params) != len(want) {
+		t.Errorf("params = %v, want %v", params, want)
+	}
+	for key, value := range want {
+		if params[key] != value {
+			t.Errorf("%s = %s, want %s", key, params[key], value)
+		}
+	}
+	if params := OrderBy(Invoice.Date.Asc()).Params(); params["where"] != "" || params["order"] != "Date ASC" {
+		t.Errorf("params without where = %v", params)
+	}
+}
@@ -0,0 +1,195 @@
+package xerotime
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// windowsZones maps the Windows time zones used by Xero for the timezone of an
+// organisation, e.g. NEWZEALANDSTANDARDTIME, to their IANA names, following the
+// world mapping of the CLDR windowsZones table. The keys are the Windows names,
+// Location compares them once normalised with normaliseZone
+var windowsZones = map[string]string{
+	"AFGHANISTAN STANDARD TIME":       "Asia/Kabul",
+	"ALASKAN STANDARD TIME":           "America/Anchorage",
+	"ALEUTIAN STANDARD TIME":          "America/Adak",
+	"ALTAI STANDARD TIME":             "Asia/Barnaul",
+	"ARAB STANDARD TIME":              "Asia/Riyadh",
+	"ARABIAN STANDARD TIME":           "Asia/Dubai",
+	"ARABIC STANDARD TIME":            "Asia/Baghdad",
+	"ARGENTINA STANDARD TIME":         "America/Buenos_Aires",
+	"ASTRAKHAN STANDARD TIME":         "Europe/Astrakhan",
+	"ATLANTIC STANDARD TIME":          "America/Halifax",
+	"AUS CENTRAL STANDARD TIME":       "Australia/Darwin",
+	"AUS CENTRAL W. STANDARD TIME":    "Australia/Eucla",
+	"AUS EASTERN STANDARD TIME":       "Australia/Sydney",
+	"AZERBAIJAN STANDARD TIME":        "Asia/Baku",
+	"AZORES STANDARD TIME":            "Atlantic/Azores",
+	"BAHIA STANDARD TIME":             "America/Bahia",
+	"BANGLADESH STANDARD TIME":        "Asia/Dhaka",
+	"BELARUS STANDARD TIME":           "Europe/Minsk",
+	"BOUGAINVILLE STANDARD TIME":      "Pacific/Bougainville",
+	"CANADA CENTRAL STANDARD TIME":    "America/Regina",
+	"CAPE VERDE STANDARD TIME":        "Atlantic/Cape_Verde",
+	"CAUCASUS STANDARD TIME":          "Asia/Yerevan",
+	"CEN. AUSTRALIA STANDARD TIME":    "Australia/Adelaide",
+	"CENTRAL AMERICA STANDARD TIME":   "America/Guatemala",
+	"CENTRAL ASIA STANDARD TIME":      "Asia/Almaty",
+	"CENTRAL BRAZILIAN STANDARD TIME": "America/Cuiaba",
+	"CENTRAL EUROPE STANDARD TIME":    "Europe/Budapest",
+	"CENTRAL EUROPEAN STANDARD TIME":  "Europe/Warsaw",
+	"CENTRAL PACIFIC STANDARD TIME":   "Pacific/Guadalcanal",
+	"CENTRAL STANDARD TIME":           "America/Chicago",
+	"CENTRAL STANDARD TIME (MEXICO)":  "America/Mexico_City",
+	"CHATHAM ISLANDS STANDARD TIME":   "Pacific/Chatham",
+	"CHINA STANDARD TIME":             "Asia/Shanghai",
+	"CUBA STANDARD TIME":              "America/Havana",
+	"DATELINE STANDARD TIME":          "Etc/GMT+12",
+	"E. AFRICA STANDARD TIME":         "Africa/Nairobi",
+	"E. AUSTRALIA STANDARD TIME":      "Australia/Brisbane",
+	"E. EUROPE STANDARD TIME":         "Europe/Chisinau",
+	"E. SOUTH AMERICA STANDARD TIME":  "America/Sao_Paulo",
+	"EASTER ISLAND STANDARD TIME":     "Pacific/Easter",
+	"EASTERN STANDARD TIME":           "America/New_York",
+	"EASTERN STANDARD TIME (MEXICO)":  "America/Cancun",
+	"EGYPT STANDARD TIME":             "Africa/Cairo",
+	"EKATERINBURG STANDARD TIME":      "Asia/Yekaterinburg",
+	"FIJI STANDARD TIME":              "Pacific/Fiji",
+	"FLE STANDARD TIME":               "Europe/Kiev",
+	"GEORGIAN STANDARD TIME":          "Asia/Tbilisi",
+	"GMT STANDARD TIME":               "Europe/London",
+	"GREENLAND STANDARD TIME":         "America/Godthab",
+	"GREENWICH STANDARD TIME":         "Atlantic/Reykjavik",
+	"GTB STANDARD TIME":               "Europe/Bucharest",
+	"HAITI STANDARD TIME":             "America/Port-au-Prince",
+	"HAWAIIAN STANDARD TIME":          "Pacific/Honolulu",
+	"INDIA STANDARD TIME":             "Asia/Calcutta",
+	"IRAN STANDARD TIME":              "Asia/Tehran",
+	"ISRAEL STANDARD TIME":            "Asia/Jerusalem",
+	"JORDAN STANDARD TIME":            "Asia/Amman",
+	"KALININGRAD STANDARD TIME":       "Europe/Kaliningrad",
+	"KAMCHATKA STANDARD TIME":         "Asia/Kamchatka",
+	"KOREA STANDARD TIME":             "Asia/Seoul",
+	"LIBYA STANDARD TIME":             "Africa/Tripoli",
+	"LINE ISLANDS STANDARD TIME":      "Pacific/Kiritimati",
+	"LORD HOWE STANDARD TIME":         "Australia/Lord_Howe",
+	"MAGADAN STANDARD TIME":           "Asia/Magadan",
+	"MAGALLANES STANDARD TIME":        "America/Punta_Arenas",
+	"MARQUESAS STANDARD TIME":         "Pacific/Marquesas",
+	"MAURITIUS STANDARD TIME":         "Indian/Mauritius",
+	"MID-ATLANTIC STANDARD TIME":      "Etc/GMT+2",
+	"MIDDLE EAST STANDARD TIME":       "Asia/Beirut",
+	"MONTEVIDEO STANDARD TIME":        "America/Montevideo",
+	"MOROCCO STANDARD TIME":           "Africa/Casablanca",
+	"MOUNTAIN STANDARD TIME":          "America/Denver",
+	"MOUNTAIN STANDARD TIME (MEXICO)": "America/Chihuahua",
+	"MYANMAR STANDARD TIME":           "Asia/Rangoon",
+	"N. CENTRAL ASIA STANDARD TIME":   "Asia/Novosibirsk",
+	"NAMIBIA STANDARD TIME":           "Africa/Windhoek",
+	"NEPAL STANDARD TIME":             "Asia/Katmandu",
+	"NEW ZEALAND STANDARD TIME":       "Pacific/Auckland",
+	"NEWFOUNDLAND STANDARD TIME":      "America/St_Johns",
+	"NORFOLK STANDARD TIME":           "Pacific/Norfolk",
+	"NORTH ASIA EAST STANDARD TIME":   "Asia/Irkutsk",
+	"NORTH ASIA STANDARD TIME":        "Asia/Krasnoyarsk",
+	"NORTH KOREA STANDARD TIME":       "Asia/Pyongyang",
+	"OMSK STANDARD TIME":              "Asia/Omsk",
+	"PACIFIC SA STANDARD TIME":        "America/Santiago",
+	"PACIFIC STANDARD TIME":           "America/Los_Angeles",
+	"PACIFIC STANDARD TIME (MEXICO)":  "America/Tijuana",
+	"PAKISTAN STANDARD TIME":          "Asia/Karachi",
+	"PARAGUAY STANDARD TIME":          "America/Asuncion",
+	"QYZYLORDA STANDARD TIME":         "Asia/Qyzylorda",
+	"ROMANCE STANDARD TIME":           "Europe/Paris",
+	"RUSSIA TIME ZONE 10":             "Asia/Srednekolymsk",
+	"RUSSIA TIME ZONE 11":             "Asia/Kamchatka",
+	"RUSSIA TIME ZONE 3":              "Europe/Samara",
+	"RUSSIAN STANDARD TIME":           "Europe/Moscow",
+	"SA EASTERN STANDARD TIME":        "America/Cayenne",
+	"SA PACIFIC STANDARD TIME":        "America/Bogota",
+	"SA WESTERN STANDARD TIME":        "America/La_Paz",
+	"SAINT PIERRE STANDARD TIME":      "America/Miquelon",
+	"SAKHALIN STANDARD TIME":          "Asia/Sakhalin",
+	"SAMOA STANDARD TIME":             "Pacific/Apia",
+	"SAO TOME STANDARD TIME":          "Africa/Sao_Tome",
+	"SARATOV STANDARD TIME":           "Europe/Saratov",
+	"SE ASIA STANDARD TIME":           "Asia/Bangkok",
+	"SINGAPORE STANDARD TIME":         "Asia/Singapore",
+	"SOUTH AFRICA STANDARD TIME":      "Africa/Johannesburg",
+	"SOUTH SUDAN STANDARD TIME":       "Africa/Juba",
+	"SRI LANKA STANDARD TIME":         "Asia/Colombo",
+	"SUDAN STANDARD TIME":             "Africa/Khartoum",
+	"SYRIA STANDARD TIME":             "Asia/Damascus",
+	"TAIPEI STANDARD TIME":            "Asia/Taipei",
+	"TASMANIA STANDARD TIME":          "Australia/Hobart",
+	"TOCANTINS STANDARD TIME":         "America/Araguaina",
+	"TOKYO STANDARD TIME":             "Asia/Tokyo",
+	"TOMSK STANDARD TIME":             "Asia/Tomsk",
+	"TONGA STANDARD TIME":             "Pacific/Tongatapu",
+	"TRANSBAIKAL STANDARD TIME":       "Asia/Chita",
+	"TURKEY STANDARD TIME":            "Europe/Istanbul",
+	"TURKS AND CAICOS STANDARD TIME":  "America/Grand_Turk",
+	"ULAANBAATAR STANDARD TIME":       "Asia/Ulaanbaatar",
+	"US EASTERN STANDARD TIME":        "America/Indianapolis",
+	"US MOUNTAIN STANDARD TIME":       "America/Phoenix",
+	"UTC":                             "UTC",
+	"UTC+12":                          "Etc/GMT-12",
+	"UTC+13":                          "Etc/GMT-13",
+	"UTC-02":                          "Etc/GMT+2",
+	"UTC-08":                          "Etc/GMT+8",
+	"UTC-09":                          "Etc/GMT+9",
+	"UTC-11":                          "Etc/GMT+11",
+	"VENEZUELA STANDARD TIME":         "America/Caracas",
+	"VLADIVOSTOK STANDARD TIME":       "Asia/Vladivostok",
+	"VOLGOGRAD STANDARD TIME":         "Europe/Volgograd",
+	"W. AUSTRALIA STANDARD TIME":      "Australia/Perth",
+	"W. CENTRAL AFRICA STANDARD TIME": "Africa/Lagos",
+	"W. EUROPE STANDARD TIME":         "Europe/Berlin",
+	"W. MONGOLIA STANDARD TIME":       "Asia/Hovd",
+	"WEST ASIA STANDARD TIME":         "Asia/Tashkent",
+	"WEST BANK STANDARD TIME":         "Asia/Hebron",
+	"WEST PACIFIC STANDARD TIME":      "Pacific/Port_Moresby",
+	"YAKUTSK STANDARD TIME":           "Asia/Yakutsk",
+	"YUKON STANDARD TIME":             "America/Whitehorse",
+}
+
+var (
+	zonesOnce   sync.Once
+	zonesByName map[string]string
+)
+
+// normaliseZone will drop the spaces, dots, signs and brackets of a Windows
+// time zone, Xero sends them as NEWZEALANDSTANDARDTIME, MIDATLANTICSTANDARDTIME
+// or UTC02
+func normaliseZone(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '.', '-', '+', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(name))
+}
+
+// Location function will return the location of the given Xero timezone, such
+// as NEWZEALANDSTANDARDTIME. IANA names, such as Pacific/Auckland, are
+// accepted as well
+func Location(timezone string) (*time.Location, error) {
+	zonesOnce.Do(func() {
+		zonesByName = make(map[string]string, len(windowsZones))
+		for windows, iana := range windowsZones {
+			zonesByName[normaliseZone(windows)] = iana
+		}
+	})
+	if iana, ok := zonesByName[normaliseZone(timezone)]; ok {
+		return time.LoadLocation(iana)
+	}
+	if timezone != "" {
+		if loc, err := time.LoadLocation(timezone); err == nil {
+			return loc, nil
+		}
+	}
+	return nil, fmt.Errorf("xerotime: unknown timezone %q", timezone)
+}
@@ -0,0 +1,178 @@
+// Package xerotime gives the types used for the dates sent and received by
+// the Xero API. They read the .Net JSON format used by Xero,
+// /Date(1494201600000+0000)/, as well as plain dates and ISO timestamps
+package xerotime
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	// isoLayout is used by Xero for the timestamps without a zone, such as
+	// DateString
+	isoLayout = "2006-01-02T15:04:05.999999999"
+)
+
+// dotNetDate matches the .Net JSON date format, the milliseconds since the
+// epoch followed by an optional offset in hours and minutes
+var dotNetDate = regexp.MustCompile(`^/Date\((-?\d+)([+-]\d{4})?\)/$`)
+
+var (
+	null         = []byte("null")
+	dotNetPrefix = []byte(`"/Date(`)
+)
+
+// Parse function will read a date sent by Xero, in the .Net JSON format,
+// YYYY-MM-DD, an ISO timestamp without zone, which is taken as UTC, or RFC3339.
+// An empty string gives the zero time
+func Parse(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if match := dotNetDate.FindStringSubmatch(value); match != nil {
+		ms, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		t := time.Unix(0, ms*int64(time.Millisecond)).UTC()
+		if match[2] == "" {
+			return t, nil
+		}
+		hours, _ := strconv.Atoi(match[2][1:3])
+		minutes, _ := strconv.Atoi(match[2][3:])
+		offset := hours*3600 + minutes*60
+		if match[2][0] == '-' {
+			offset = -offset
+		}
+		return t.In(time.FixedZone("", offset)), nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, isoLayout, dateLayout} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("xerotime: unknown date format %q", value)
+}
+
+// unmarshal will read the JSON value of a date, null and "" give the zero
+// time
+func unmarshal(data []byte) (time.Time, error) {
+	if bytes.Equal(data, null) {
+		return time.Time{}, nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return time.Time{}, err
+	}
+	return Parse(value)
+}
+
+// DateTime is an instant returned by Xero, such as UpdatedDateUTC. The zero
+// value is sent as null, the accounting models leave it out instead
+type DateTime struct {
+	time.Time
+}
+
+// NewDateTime function will build a DateTime from the given time
+func NewDateTime(t time.Time) DateTime {
+	return DateTime{Time: t}
+}
+
+// MarshalJSON method will send the time in RFC3339
+func (d DateTime) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return null, nil
+	}
+	return json.Marshal(d.Format(time.RFC3339))
+}
+
+// UnmarshalJSON method will read the time in any of the formats known by
+// Parse
+func (d *DateTime) UnmarshalJSON(data []byte) error {
+	t, err := unmarshal(data)
+	if err != nil {
+		return err
+	}
+	d.Time = t
+	return nil
+}
+
+// String method will return the time in RFC3339, or an empty string for the
+// zero value
+func (d DateTime) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format(time.RFC3339)
+}
+
+// Date is a calendar day, such as the date or due date of an invoice. It's
+// kept as midnight UTC and the zero value is sent as null, the accounting
+// models leave it out instead
+type Date struct {
+	time.Time
+}
+
+// NewDate function will build the Date of the given year, month and day
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// DateOf function will return the day of the given time, in its own location
+func DateOf(t time.Time) Date {
+	if t.IsZero() {
+		return Date{}
+	}
+	return NewDate(t.Date())
+}
+
+// InLocation method will return the start of the day in the given location,
+// use it with the location of the organisation to get the instant a date
+// field begins for it
+func (d Date) InLocation(loc *time.Location) time.Time {
+	if d.IsZero() {
+		return time.Time{}
+	}
+	year, month, day := d.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+// MarshalJSON method will send the date as YYYY-MM-DD
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return null, nil
+	}
+	return json.Marshal(d.Format(dateLayout))
+}
+
+// UnmarshalJSON method will read the date in any of the formats known by
+// Parse, the time of the day is dropped. The .Net dates hold midnight UTC of
+// the day, so their offset is ignored, it would move the day back for the
+// zones behind UTC
+func (d *Date) UnmarshalJSON(data []byte) error {
+	t, err := unmarshal(data)
+	if err != nil {
+		return err
+	}
+	if bytes.HasPrefix(data, dotNetPrefix) {
+		t = t.UTC()
+	}
+	*d = DateOf(t)
+	return nil
+}
+
+// String method will return the date as YYYY-MM-DD, or an empty string for
+// the zero value
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format(dateLayout)
+}
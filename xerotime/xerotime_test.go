@@ -0,0 +1,154 @@
+package xerotime
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Time
+		offset int
+	}{
+		{"dotnet with offset", "/Date(1494201600000+1300)/", time.Date(2017, 5, 8, 0, 0, 0, 0, time.UTC), 13 * 3600},
+		{"dotnet with negative offset", "/Date(1494201600000-0530)/", time.Date(2017, 5, 8, 0, 0, 0, 0, time.UTC), -(5*3600 + 30*60)},
+		{"dotnet with zero offset", "/Date(1494201600000+0000)/", time.Date(2017, 5, 8, 0, 0, 0, 0, time.UTC), 0},
+		{"dotnet without offset", "/Date(1518685950940)/", time.Date(2018, 2, 15, 9, 12, 30, 940000000, time.UTC), 0},
+		{"dotnet before the epoch", "/Date(-86400000+0000)/", time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC), 0},
+		{"DateString", "2017-05-08T10:30:00", time.Date(2017, 5, 8, 10, 30, 0, 0, time.UTC), 0},
+		{"DateString with fraction", "2018-02-15T09:12:30.94", time.Date(2018, 2, 15, 9, 12, 30, 940000000, time.UTC), 0},
+		{"date only", "2017-05-08", time.Date(2017, 5, 8, 0, 0, 0, 0, time.UTC), 0},
+		{"RFC3339", "2017-05-08T13:00:00+13:00", time.Date(2017, 5, 8, 0, 0, 0, 0, time.UTC), 13 * 3600},
+		{"empty", "", time.Time{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Parse(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+			if _, offset := got.Zone(); offset != tt.offset {
+				t.Errorf("Parse(%q) offset = %d, want %d", tt.value, offset, tt.offset)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, value := range []string{"/Date(abc)/", "/Date(1494201600000+13)/", "08/05/2017", "yesterday"} {
+		if got, err := Parse(value); err == nil {
+			t.Errorf("Parse(%q) = %v, want an error", value, got)
+		}
+	}
+}
+
+func TestDateJSON(t *testing.T) {
+	tests := []struct {
+		data string
+		want Date
+	}{
+		{`"/Date(1494201600000+0000)/"`, NewDate(2017, 5, 8)},
+		{`"/Date(1494201600000+1300)/"`, NewDate(2017, 5, 8)},
+		{`"/Date(1494201600000-0500)/"`, NewDate(2017, 5, 8)},
+		{`"/Date(1494201600000-1000)/"`, NewDate(2017, 5, 8)},
+		{`"/Date(1494201600000)/"`, NewDate(2017, 5, 8)},
+		{`"2017-05-08T00:00:00"`, NewDate(2017, 5, 8)},
+		{`"2017-05-08"`, NewDate(2017, 5, 8)},
+		{`""`, Date{}},
+		{`null`, Date{}},
+	}
+	for _, tt := range tests {
+		var got Date
+		if err := json.Unmarshal([]byte(tt.data), &got); err != nil {
+			t.Errorf("Unmarshal(%s): %v", tt.data, err)
+			continue
+		}
+		if !got.Equal(tt.want.Time) {
+			t.Errorf("Unmarshal(%s) = %v, want %v", tt.data, got, tt.want)
+		}
+	}
+
+	data, err := json.Marshal(NewDate(2017, 5, 8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `"2017-05-08"` {
+		t.Errorf("Marshal = %s, want \"2017-05-08\"", data)
+	}
+}
+
+func TestDateTimeJSON(t *testing.T) {
+	var got DateTime
+	if err := json.Unmarshal([]byte(`"/Date(1518685950940+0000)/"`), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2018, 2, 15, 9, 12, 30, 940000000, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("Unmarshal = %v, want %v", got, want)
+	}
+
+	data, err := json.Marshal(NewDateTime(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `"2018-02-15T09:12:30Z"` {
+		t.Errorf("Marshal = %s, want \"2018-02-15T09:12:30Z\"", data)
+	}
+}
+
+func TestLocation(t *testing.T) {
+	tests := []struct {
+		timezone string
+		want     string
+	}{
+		{"NEWZEALANDSTANDARDTIME", "Pacific/Auckland"},
+		{"New Zealand Standard Time", "Pacific/Auckland"},
+		{"MOROCCOSTANDARDTIME", "Africa/Casablanca"},
+		{"JORDANSTANDARDTIME", "Asia/Amman"},
+		{"GEORGIANSTANDARDTIME", "Asia/Tbilisi"},
+		{"YAKUTSKSTANDARDTIME", "Asia/Yakutsk"},
+		{"VLADIVOSTOKSTANDARDTIME", "Asia/Vladivostok"},
+		{"CENAUSTRALIASTANDARDTIME", "Australia/Adelaide"},
+		{"CENTRALSTANDARDTIMEMEXICO", "America/Mexico_City"},
+		{"MIDATLANTICSTANDARDTIME", "Etc/GMT+2"},
+		{"Mid-Atlantic Standard Time", "Etc/GMT+2"},
+		{"UTC02", "Etc/GMT+2"},
+		{"UTC12", "Etc/GMT-12"},
+		{"Europe/London", "Europe/London"},
+	}
+	for _, tt := range tests {
+		loc, err := Location(tt.timezone)
+		if err != nil {
+			t.Errorf("Location(%q): %v", tt.timezone, err)
+			continue
+		}
+		if loc.String() != tt.want {
+			t.Errorf("Location(%q) = %s, want %s", tt.timezone, loc, tt.want)
+		}
+	}
+
+	for _, timezone := range []string{"", "NOWHERESTANDARDTIME"} {
+		if _, err := Location(timezone); err == nil {
+			t.Errorf("Location(%q) gave no error", timezone)
+		}
+	}
+}
+
+func TestWindowsZones(t *testing.T) {
+	normalised := make(map[string]string, len(windowsZones))
+	for windows, iana := range windowsZones {
+		if other, ok := normalised[normaliseZone(windows)]; ok {
+			t.Errorf("%s and %s are the same once normalised", windows, other)
+		}
+		normalised[normaliseZone(windows)] = windows
+		if _, err := time.LoadLocation(iana); err != nil {
+			t.Errorf("%s: %v", windows, err)
+		}
+	}
+}